@@ -0,0 +1,74 @@
+package main
+
+// Animation is a looping sequence of tilesheet indices, each shown for
+// ticksPerFrame ticks.
+type Animation struct {
+	frames        []int
+	ticksPerFrame int
+}
+
+// Frame returns the tilesheet index to show tick ticks into the animation.
+func (a *Animation) Frame(tick int) int {
+	if len(a.frames) == 0 {
+		return 0
+	}
+	if a.ticksPerFrame <= 0 {
+		return a.frames[0]
+	}
+	return a.frames[(tick/a.ticksPerFrame)%len(a.frames)]
+}
+
+// The two frames the climb animation alternates between, as 0-based
+// tilesheet indices, and how long each is held.
+const (
+	playerClimbFrameA     = 286
+	playerClimbFrameB     = 280
+	playerClimbFrameTicks = 10
+)
+
+// Player animations, as 0-based tilesheet indices.
+var (
+	playerIdleAnim  = &Animation{frames: []int{280}, ticksPerFrame: 1}
+	playerWalkAnim  = &Animation{frames: []int{281, 282, 283, 282}, ticksPerFrame: 6}
+	playerJumpAnim  = &Animation{frames: []int{285}, ticksPerFrame: 1}
+	playerClimbAnim = &Animation{frames: []int{playerClimbFrameA, playerClimbFrameB}, ticksPerFrame: playerClimbFrameTicks}
+)
+
+// playerAttackSprite is shown instead of the current animation while a melee
+// attack is out.
+const playerAttackSprite = 284
+
+// updateAnimation picks the animation for the player's current state and
+// advances it one tick. Switching animations restarts from the first frame.
+func (p *Player) updateAnimation() {
+	anim := playerIdleAnim
+	switch {
+	case p.onLadder || p.onRope:
+		anim = playerClimbAnim
+	case p.isJumping || !p.onGround:
+		anim = playerJumpAnim
+	case p.vx != 0:
+		anim = playerWalkAnim
+	}
+	if anim != p.anim {
+		p.anim = anim
+		p.animTick = 0
+		return
+	}
+	// Climbing only animates while actually moving on the ladder.
+	if anim == playerClimbAnim && p.vy == 0 {
+		return
+	}
+	p.animTick++
+}
+
+// spriteIndex returns the tilesheet index to draw the player with.
+func (p *Player) spriteIndex() int {
+	if p.attackTimer > 0 {
+		return playerAttackSprite
+	}
+	if p.anim == nil {
+		return playerIdleAnim.Frame(0)
+	}
+	return p.anim.Frame(p.animTick)
+}
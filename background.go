@@ -0,0 +1,78 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// backgroundPass is one step of drawing the background: a pre-rendered image
+// of the static tiles of a run of layers, then the animated tiles of the
+// run's last layer on top. Splitting at each layer with animated tiles keeps
+// them in that layer's turn, under the layers above it.
+type backgroundPass struct {
+	image    *ebiten.Image
+	animated int // index of the layer whose animated tiles follow image, or -1
+}
+
+// buildBackground renders the static tiles of every background layer of the
+// current map, apart from the parallax ones, into as few map-sized images as
+// the animated tiles allow, so Draw can blit them instead of drawing each
+// tile every frame. Call it again whenever those layers change.
+func (g *Game) buildBackground() {
+	for _, pass := range g.background {
+		pass.image.Deallocate()
+	}
+	g.background = g.background[:0]
+	w, h := g.tilemap.Width*g.cfg.TileSize, g.tilemap.Height*g.cfg.TileSize
+	if w <= 0 || h <= 0 {
+		return
+	}
+	var img *ebiten.Image
+	for i := range g.tilemap.Layers {
+		l := &g.tilemap.Layers[i]
+		if !isDecorLayer(l) || isForegroundLayer(l) || isParallaxLayer(l) {
+			continue
+		}
+		if img == nil {
+			img = ebiten.NewImage(w, h)
+		}
+		hasAnimated := false
+		for j, raw := range l.Data {
+			if g.tilemap.tiles.animated(tileGID(raw)) {
+				hasAnimated = true
+				continue
+			}
+			g.drawTile(img, raw, j%l.Width, j/l.Width, 0, 0, l.Opacity)
+		}
+		if hasAnimated {
+			g.background = append(g.background, backgroundPass{image: img, animated: i})
+			img = nil
+		}
+	}
+	if img != nil {
+		g.background = append(g.background, backgroundPass{image: img, animated: -1})
+	}
+}
+
+// drawBackground blits the pre-rendered background at the camera offset,
+// drawing each layer's on-screen animated tiles in between.
+func (g *Game) drawBackground(screen *ebiten.Image) {
+	for _, pass := range g.background {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(-g.camera.x, -g.camera.y)
+		screen.DrawImage(pass.image, op)
+		if pass.animated < 0 {
+			continue
+		}
+		l := &g.tilemap.Layers[pass.animated]
+		x0, y0, x1, y1 := g.camera.visibleTiles(l.Width, l.Height)
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				i := y*l.Width + x
+				if i >= len(l.Data) {
+					break
+				}
+				if raw := l.Data[i]; g.tilemap.tiles.animated(tileGID(raw)) {
+					g.drawTile(screen, raw, x, y, g.camera.x, g.camera.y, l.Opacity)
+				}
+			}
+		}
+	}
+}
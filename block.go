@@ -0,0 +1,93 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+const (
+	blockSpriteIndex = 26
+	blockPushFactor  = 0.5 // walking speed kept while pushing a block
+)
+
+// Block is a tile-sized crate the player can shove along the ground. It's
+// solid to the player, falls under gravity, and can be stood on.
+type Block struct {
+	x, y float64
+	vy   float64
+}
+
+// loadBlocks creates a Block for every object of type "block".
+func loadBlocks(m *TiledMap) []Block {
+	var blocks []Block
+	for _, o := range m.objectsOfType("block") {
+		blocks = append(blocks, Block{x: o.X, y: o.Y})
+	}
+	return blocks
+}
+
+// blockAt returns the index of a block that a box at (x, y) of size w x h
+// overlaps, skipping the block at index skip, or -1 if there's none. Blocks
+// are s pixels, one tile, on a side.
+func blockAt(blocks []Block, s, x, y, w, h float64, skip int) int {
+	for i, b := range blocks {
+		if i != skip && aabbOverlap(x, y, w, h, b.x, b.y, s, s) {
+			return i
+		}
+	}
+	return -1
+}
+
+// blocked reports whether block i, s pixels on a side, would hit a solid
+// tile or another block at (x, y). Like collides, the right and bottom edges
+// are exclusive.
+func (b *Block) blocked(x, y, s float64, collision *Layer, blocks []Block, i int) bool {
+	if x < 0 || (collision != nil && x+s > float64(collision.Width)*s) {
+		return true
+	}
+	if collision != nil {
+		left, right := tileSpan(x, s, s)
+		top, bottom := tileSpan(y, s, s)
+		for ty := top; ty <= bottom; ty++ {
+			for tx := left; tx <= right; tx++ {
+				if collision.solidAt(tx, ty) {
+					return true
+				}
+			}
+		}
+	}
+	return blockAt(blocks, s, x, y, s, s, i) >= 0
+}
+
+// pushBlock moves block i dx pixels sideways if there's room for it. It
+// reports whether the block moved.
+func pushBlock(blocks []Block, i int, s, dx float64, collision *Layer) bool {
+	b := &blocks[i]
+	if b.blocked(b.x+dx, b.y, s, collision, blocks, i) {
+		return false
+	}
+	b.x += dx
+	return true
+}
+
+// updateBlocks drops every block under gravity, no faster than maxFall,
+// until it lands on the ground or another block.
+func updateBlocks(blocks []Block, collision *Layer, s, gravity, maxFall, k float64) {
+	for i := range blocks {
+		b := &blocks[i]
+		b.vy = min(b.vy+gravity*k, maxFall)
+		newY := b.y + b.vy*k
+		if !b.blocked(b.x, newY, s, collision, blocks, i) {
+			b.y = newY
+			continue
+		}
+		b.y = sweep(b.y, newY, func(y float64) bool { return !b.blocked(b.x, y, s, collision, blocks, i) })
+		b.vy = 0
+	}
+}
+
+// drawBlocks renders the blocks.
+func drawBlocks(screen, tiles *ebiten.Image, blocks []Block, cam *Camera) {
+	for _, b := range blocks {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(b.x-cam.x, b.y-cam.y)
+		screen.DrawImage(spriteAt(tiles, blockSpriteIndex, cam.tileSize), op)
+	}
+}
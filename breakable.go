@@ -0,0 +1,31 @@
+package main
+
+// breakStompSpeed is how fast, in pixels per reference tick, the player has
+// to be falling to smash through a breakable tile instead of landing on it.
+const breakStompSpeed = 4.0
+
+// getBreakableLayer returns the layer named "Breakable".
+func getBreakableLayer(layers []Layer) *Layer {
+	return getLayerByName(layers, "Breakable")
+}
+
+// breakTiles clears every tile of the Breakable layer that the player's box
+// at (x, y) overlaps, so they no longer collide or draw. It reports whether
+// anything broke; the caller rebuilds the background if so.
+func (p *Player) breakTiles(breakable *Layer, x, y float64) bool {
+	ts := float64(p.tileSize)
+	left, right := tileSpan(x, p.width, ts)
+	top, bottom := tileSpan(y, p.height, ts)
+	broke := false
+	for ty := top; ty <= bottom; ty++ {
+		for tx := left; tx <= right; tx++ {
+			if breakable.tileAt(tx, ty) == 0 {
+				continue
+			}
+			breakable.Data[ty*breakable.Width+tx] = 0
+			broke = true
+			debugf("breakTiles - Broke tile at (%d, %d)", tx, ty)
+		}
+	}
+	return broke
+}
@@ -0,0 +1,195 @@
+package main
+
+import "math"
+
+const (
+	cameraFollow       = 0.2  // fraction of the distance to the target covered each tick
+	cameraLadderFollow = 0.06 // gentler vertical follow while climbing, so each rung doesn't jolt the view
+)
+
+// Camera is the top-left corner of the visible region in world pixels.
+type Camera struct {
+	x, y float64
+
+	// deadzoneW x deadzoneH is a box centered on screen that the player can
+	// move around in without the camera moving at all. Zero means the camera
+	// keeps the player centered.
+	deadzoneW, deadzoneH float64
+
+	// autoScroll, when non-zero, moves the camera right this many pixels per
+	// tick regardless of where the player is. It comes from the level's
+	// "autoscroll" property.
+	autoScroll float64
+
+	// zoom is a whole-number magnification. The view covers 1/zoom of the
+	// screen in each direction; 0 and 1 both mean no zoom.
+	zoom int
+
+	// shakeTimer counts down the current screen shake, which started at
+	// shakeTicks with an offset of up to shakeMagnitude pixels and fades
+	// as it runs out. Draw applies it; see Shake.
+	shakeTimer, shakeTicks int
+	shakeMagnitude         float64
+
+	// screenW x screenH is the internal resolution the view fills, and
+	// tileSize the side of a map tile, both from the game's Config.
+	screenW, screenH, tileSize int
+}
+
+// newCamera returns a camera at the origin sized for cfg, with the default
+// deadzone.
+func newCamera(cfg Config) Camera {
+	c := Camera{deadzoneW: 32, deadzoneH: 24}
+	c.screenW, c.screenH, c.tileSize = cfg.ScreenWidth, cfg.ScreenHeight, cfg.TileSize
+	return c
+}
+
+// view returns the size of the visible world region in pixels: the screen
+// size divided by the zoom.
+func (c *Camera) view() (float64, float64) {
+	s := float64(max(c.zoom, 1))
+	return float64(c.screenW) / s, float64(c.screenH) / s
+}
+
+// focus returns the point the camera follows, the midpoint of the players'
+// centers, and whether they're all climbing.
+func focus(players []Player) (cx, cy float64, climbing bool) {
+	climbing = len(players) > 0
+	for _, p := range players {
+		cx += p.x + p.width/2
+		cy += p.y + p.height/2
+		climbing = climbing && p.onLadder
+	}
+	n := float64(max(len(players), 1))
+	return cx / n, cy / n, climbing
+}
+
+// target returns where the camera wants to be: unchanged while the focus
+// point (cx, cy) is inside the deadzone, otherwise just far enough to put it
+// back on its edge.
+func (c *Camera) target(cx, cy float64) (float64, float64) {
+	vw, vh := c.view()
+	return deadzoneTarget(c.x, cx, vw, c.deadzoneW),
+		deadzoneTarget(c.y, cy, vh, c.deadzoneH)
+}
+
+// deadzoneTarget works on a single axis: cam is the camera position, pos the
+// player's center, view the screen size, and zone the deadzone size.
+func deadzoneTarget(cam, pos, view, zone float64) float64 {
+	lo := cam + (view-zone)/2
+	hi := lo + zone
+	switch {
+	case pos < lo:
+		return cam - (lo - pos)
+	case pos > hi:
+		return cam + (pos - hi)
+	}
+	return cam
+}
+
+// Update eases the camera toward the players and keeps it inside the map.
+// With more than one player it frames the point between them. While
+// everyone is on a ladder the vertical follow is slowed down; it returns to
+// normal as soon as someone gets off.
+func (c *Camera) Update(players []Player, mapW, mapH float64) {
+	cx, cy, climbing := focus(players)
+	tx, ty := c.target(cx, cy)
+	followY := cameraFollow
+	if climbing {
+		followY = cameraLadderFollow
+	}
+	if c.autoScroll != 0 {
+		c.x += c.autoScroll
+	} else {
+		c.x += (tx - c.x) * cameraFollow
+	}
+	c.y += (ty - c.y) * followY
+	c.frame(players)
+	c.clamp(mapW, mapH)
+	c.updateShake()
+}
+
+// Snap centers the camera on the players with no easing.
+func (c *Camera) Snap(players []Player, mapW, mapH float64) {
+	vw, vh := c.view()
+	cx, cy, _ := focus(players)
+	c.x = cx - vw/2
+	c.y = cy - vh/2
+	c.frame(players)
+	c.clamp(mapW, mapH)
+}
+
+// frame moves the camera the least it can to get every player fully in
+// view, when there's more than one. Game.leashPlayer keeps them close
+// enough together that it usually can; if they're further apart it leaves
+// the camera alone.
+func (c *Camera) frame(players []Player) {
+	if len(players) < 2 {
+		return
+	}
+	vw, vh := c.view()
+	x0, y0, x1, y1 := playerBounds(players, -1)
+	if x1-x0 <= vw {
+		c.x = min(max(c.x, x1-vw), x0)
+	}
+	if y1-y0 <= vh {
+		c.y = min(max(c.y, y1-vh), y0)
+	}
+}
+
+// playerBounds returns the box around every player's bounding box except
+// player skip's; pass -1 to skip none.
+func playerBounds(players []Player, skip int) (x0, y0, x1, y1 float64) {
+	x0, y0 = math.Inf(1), math.Inf(1)
+	x1, y1 = math.Inf(-1), math.Inf(-1)
+	for i, p := range players {
+		if i == skip {
+			continue
+		}
+		x0, y0 = min(x0, p.x), min(y0, p.y)
+		x1, y1 = max(x1, p.x+p.width), max(y1, p.y+p.height)
+	}
+	return x0, y0, x1, y1
+}
+
+// clamp keeps the view inside a mapW x mapH world so nothing past the map
+// edges is shown. Maps smaller than the screen stay pinned at the origin.
+func (c *Camera) clamp(mapW, mapH float64) {
+	vw, vh := c.view()
+	c.x = min(c.x, mapW-vw)
+	c.y = min(c.y, mapH-vh)
+	c.x = max(c.x, 0)
+	c.y = max(c.y, 0)
+}
+
+// pushPlayer keeps the player inside the autoscrolling view: the trailing
+// (left) edge shoves them along and the leading edge stops them running
+// ahead. It reports whether the player was crushed, i.e. the edge pushed
+// them into a solid tile.
+func (c *Camera) pushPlayer(p *Player, collision *Layer) bool {
+	vw, _ := c.view()
+	if p.x+p.width > c.x+vw {
+		p.x = c.x + vw - p.width
+	}
+	if p.x >= c.x {
+		return false
+	}
+	if collision != nil && p.collides(c.x, p.y, collision) {
+		return true
+	}
+	p.x = c.x
+	return false
+}
+
+// visibleTiles returns the range of tile columns [x0, x1) and rows [y0, y1)
+// of a w x h tile layer that intersect the view, so drawing can skip the
+// rest of a large map.
+func (c *Camera) visibleTiles(w, h int) (x0, y0, x1, y1 int) {
+	ts := float64(c.tileSize)
+	x0 = max(int(math.Floor(c.x/ts)), 0)
+	y0 = max(int(math.Floor(c.y/ts)), 0)
+	vw, vh := c.view()
+	x1 = min(int(math.Ceil((c.x+vw)/ts)), w)
+	y1 = min(int(math.Ceil((c.y+vh)/ts)), h)
+	return x0, y0, x1, y1
+}
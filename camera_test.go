@@ -0,0 +1,152 @@
+package main
+
+import "testing"
+
+// TestCameraLadderFollow moves the player 64px down the same way twice, once
+// climbing and once not, and compares how far the camera follows in the next
+// tick.
+func TestCameraLadderFollow(t *testing.T) {
+	follow := func(onLadder bool) float64 {
+		c := newCamera(DefaultConfig())
+		p := testPlayer(400, 400)
+		c.Snap([]Player{p}, 1000, 1000)
+		start := c.y
+		p.y += 64
+		p.onLadder = onLadder
+		c.Update([]Player{p}, 1000, 1000)
+		return c.y - start
+	}
+	climbing, falling := follow(true), follow(false)
+	if climbing <= 0 || climbing >= falling {
+		t.Errorf("camera moved %vpx following a climb and %vpx following a fall; want climbing slower but still moving", climbing, falling)
+	}
+
+	// Getting off the ladder goes back to the normal speed.
+	c := newCamera(DefaultConfig())
+	p := testPlayer(400, 400)
+	p.onLadder = true
+	c.Snap([]Player{p}, 1000, 1000)
+	c.Update([]Player{p}, 1000, 1000)
+	start := c.y
+	p.y += 64
+	p.onLadder = false
+	c.Update([]Player{p}, 1000, 1000)
+	if got := c.y - start; got != falling {
+		t.Errorf("camera moved %vpx after dismounting, want the normal %vpx", got, falling)
+	}
+}
+
+func TestDeadzoneTarget(t *testing.T) {
+	// A 160px view with a 32px deadzone: at cam = 100 the zone covers
+	// 164..196.
+	tests := []struct {
+		name string
+		pos  float64
+		zone float64
+		want float64
+	}{
+		{"inside", 180, 32, 100},
+		{"on the left edge", 164, 32, 100},
+		{"on the right edge", 196, 32, 100},
+		{"past the left edge", 150, 32, 86},
+		{"past the right edge", 210, 32, 114},
+		{"no deadzone keeps the player centered", 200, 0, 120},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deadzoneTarget(100, tt.pos, 160, tt.zone); got != tt.want {
+				t.Errorf("deadzoneTarget(100, %v, 160, %v) = %v, want %v", tt.pos, tt.zone, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCameraDeadzone checks the camera stays still while the player moves
+// inside the deadzone and starts following once they leave it.
+func TestCameraDeadzone(t *testing.T) {
+	c := newCamera(DefaultConfig())
+	p := testPlayer(400, 400)
+	c.Snap([]Player{p}, 1000, 1000)
+	startX, startY := c.x, c.y
+
+	p.x += c.deadzoneW/2 - 1
+	p.y -= c.deadzoneH/2 - 1
+	for range 10 {
+		c.Update([]Player{p}, 1000, 1000)
+	}
+	if c.x != startX || c.y != startY {
+		t.Errorf("camera moved to (%v, %v) from (%v, %v) with the player inside the deadzone", c.x, c.y, startX, startY)
+	}
+
+	p.x += 20
+	c.Update([]Player{p}, 1000, 1000)
+	if c.x <= startX {
+		t.Errorf("camera at x = %v didn't follow the player out of the deadzone", c.x)
+	}
+}
+
+func TestCameraClamp(t *testing.T) {
+	tests := []struct {
+		name         string
+		x, y         float64
+		mapW, mapH   float64
+		zoom         int
+		wantX, wantY float64
+	}{
+		{"inside", 100, 50, 1000, 500, 0, 100, 50},
+		{"past the top left", -10, -5, 1000, 500, 0, 0, 0},
+		{"past the bottom right", 900, 400, 1000, 500, 0, 840, 340},
+		{"map smaller than the screen", 50, 50, 100, 100, 0, 0, 0},
+		{"zoomed in shows less", 950, 450, 1000, 500, 2, 920, 420},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCamera(DefaultConfig())
+			c.x, c.y, c.zoom = tt.x, tt.y, tt.zoom
+			c.clamp(tt.mapW, tt.mapH)
+			if c.x != tt.wantX || c.y != tt.wantY {
+				t.Errorf("clamped to (%v, %v), want (%v, %v)", c.x, c.y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func TestPushPlayer(t *testing.T) {
+	// The view is 160px wide starting at x = 100.
+	tests := []struct {
+		name      string
+		x         float64
+		collision *Layer
+		wantX     float64
+		crushed   bool
+	}{
+		{"inside the view", 150, nil, 150, false},
+		{"behind the trailing edge", 90, nil, 100, false},
+		{"past the leading edge", 250, nil, 244, false},
+		{"pushed into a wall", 80, testLayer("......#"), 80, true},
+		{"pushed clear of walls", 90, testLayer("....#.."), 100, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCamera(DefaultConfig())
+			c.x, c.autoScroll = 100, 1
+			p := testPlayer(tt.x, 0)
+			crushed := c.pushPlayer(&p, tt.collision)
+			if p.x != tt.wantX || crushed != tt.crushed {
+				t.Errorf("player at x = %v, crushed %v; want %v, %v", p.x, crushed, tt.wantX, tt.crushed)
+			}
+		})
+	}
+}
+
+func TestAutoscroll(t *testing.T) {
+	c := newCamera(DefaultConfig())
+	c.autoScroll = 0.5
+	p := testPlayer(0, 0)
+	for range 10 {
+		c.Update([]Player{p}, 1000, 1000)
+	}
+	if c.x != 5 {
+		t.Errorf("camera at x = %v after 10 ticks at 0.5px, want 5 whatever the player does", c.x)
+	}
+}
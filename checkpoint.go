@@ -0,0 +1,56 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+const checkpointSpriteIndex = 186
+
+// Checkpoint is a spot that becomes the respawn point once touched.
+type Checkpoint struct {
+	obj    Object
+	active bool
+}
+
+// loadCheckpoints creates a Checkpoint for every object of type "checkpoint".
+func loadCheckpoints(m *TiledMap) []Checkpoint {
+	var checkpoints []Checkpoint
+	for _, o := range m.objectsOfType("checkpoint") {
+		checkpoints = append(checkpoints, Checkpoint{obj: o})
+	}
+	return checkpoints
+}
+
+// checkCheckpoints activates any checkpoint a player is touching and moves
+// the respawn point onto it. Only the most recent checkpoint stays active.
+func (g *Game) checkCheckpoints() {
+	for i := range g.checkpoints {
+		c := &g.checkpoints[i]
+		o := c.obj
+		if c.active {
+			continue
+		}
+		ts := float64(g.cfg.TileSize)
+		p, ok := g.playerTouching(o.X, o.Y, max(o.Width, ts), max(o.Height, ts))
+		if !ok {
+			continue
+		}
+		for j := range g.checkpoints {
+			g.checkpoints[j].active = false
+		}
+		c.active = true
+		spot := *p
+		spot.placeAt(o)
+		g.respawnX, g.respawnY = spot.x, spot.y
+	}
+}
+
+// drawCheckpoints renders every checkpoint, tinting the active one green.
+func drawCheckpoints(screen, tiles *ebiten.Image, checkpoints []Checkpoint, cam *Camera) {
+	for _, c := range checkpoints {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(c.obj.X-cam.x, c.obj.Y-cam.y)
+		if c.active {
+			op.ColorScale.Scale(0.3, 1, 0.3, 1)
+		}
+		screen.DrawImage(spriteAt(tiles, checkpointSpriteIndex, cam.tileSize), op)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+// layerChunk is one block of an infinite map's tile layer. X and Y are in
+// tiles and may be negative.
+type layerChunk struct {
+	X, Y, Width, Height int
+	Data                []int
+}
+
+// stitchChunks turns an infinite map into an ordinary one that the rest of
+// the game can index directly. Every tile layer's chunks are copied into a
+// flat Data array covering the bounding box of all chunks in the map, and
+// the map is shifted so that box starts at tile (0, 0); object positions
+// move by the same amount. The shift is kept in originX and originY.
+func (m *TiledMap) stitchChunks() {
+	first := true
+	var minX, minY, maxX, maxY int
+	for _, l := range m.Layers {
+		for _, c := range l.chunks {
+			if first {
+				minX, minY, maxX, maxY = c.X, c.Y, c.X+c.Width, c.Y+c.Height
+				first = false
+				continue
+			}
+			minX, minY = min(minX, c.X), min(minY, c.Y)
+			maxX, maxY = max(maxX, c.X+c.Width), max(maxY, c.Y+c.Height)
+		}
+	}
+	w, h := maxX-minX, maxY-minY
+	m.Width, m.Height = w, h
+	m.originX, m.originY = minX, minY
+
+	tw, th := m.Tilewidth, m.Tileheight
+	if tw == 0 || th == 0 {
+		tw, th = defaultTileSize, defaultTileSize
+	}
+	for i := range m.Layers {
+		l := &m.Layers[i]
+		switch l.Type {
+		case "tilelayer":
+			l.Data = make([]int, w*h)
+			for _, c := range l.chunks {
+				for cy := range c.Height {
+					for cx := range c.Width {
+						if j := cy*c.Width + cx; j < len(c.Data) {
+							l.Data[(c.Y-minY+cy)*w+c.X-minX+cx] = c.Data[j]
+						}
+					}
+				}
+			}
+			l.Width, l.Height = w, h
+			l.chunks = nil
+		case "objectgroup":
+			for j := range l.Objects {
+				l.Objects[j].X -= float64(minX * tw)
+				l.Objects[j].Y -= float64(minY * th)
+			}
+		}
+	}
+	m.Infinite = false
+}
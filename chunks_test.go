@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestStitchChunks loads an infinite map whose two 2x2 chunks sit at
+// negative and positive tile coordinates, with a gap between them.
+func TestStitchChunks(t *testing.T) {
+	src := `{"infinite": true, "tilewidth": 16, "tileheight": 16, "layers": [
+	 {"name": "Collision", "type": "tilelayer", "chunks": [
+	  {"x": -2, "y": -1, "width": 2, "height": 2, "data": [1, 2, 3, 4]},
+	  {"x": 1, "y": 0, "width": 2, "height": 2, "data": [5, 6, 7, 8]}
+	 ]},
+	 {"name": "Ladders", "type": "tilelayer", "chunks": [
+	  {"x": 1, "y": 1, "width": 1, "height": 1, "data": [9]}
+	 ]},
+	 {"name": "Objects", "type": "objectgroup", "objects": [
+	  {"name": "PlayerStart", "x": -16, "y": 0, "width": 16, "height": 16}
+	 ]}
+	]}`
+	var m TiledMap
+	if err := json.Unmarshal([]byte(src), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Infinite || m.Width != 5 || m.Height != 3 || m.originX != -2 || m.originY != -1 {
+		t.Fatalf("stitched map is %dx%d with origin (%d, %d), infinite %v; want 5x3 from (-2, -1)",
+			m.Width, m.Height, m.originX, m.originY, m.Infinite)
+	}
+	collision := []int{
+		1, 2, 0, 0, 0,
+		3, 4, 0, 5, 6,
+		0, 0, 0, 7, 8,
+	}
+	if l := m.Layers[0]; l.Width != 5 || l.Height != 3 || !reflect.DeepEqual(l.Data, collision) {
+		t.Errorf("Collision is %dx%d %v, want 5x3 %v", l.Width, l.Height, l.Data, collision)
+	}
+	if got := m.Layers[1].tileAt(3, 2); got != 9 {
+		t.Errorf("Ladders tile at world (1, 1) = %d, want 9", got)
+	}
+	o, _ := m.objectNamed("PlayerStart")
+	if o.X != 16 || o.Y != 16 {
+		t.Errorf("PlayerStart at (%v, %v), want moved with the tiles to (16, 16)", o.X, o.Y)
+	}
+
+	// Collision follows the stitched data: the PlayerStart still covers
+	// tile 4, and the gap between the chunks is empty.
+	p := testPlayer(o.X, o.Y)
+	if !p.collides(o.X, o.Y, &m.Layers[0]) {
+		t.Error("no collision with tile 4 at the PlayerStart")
+	}
+	if p.collides(32, 16, &m.Layers[0]) {
+		t.Error("collision in the gap between the chunks")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+const (
+	coinSpriteIndex = 20
+	coinValue       = 1
+)
+
+// Coin is a collectible that adds to the score.
+type Coin struct {
+	x, y      float64
+	collected bool
+}
+
+// loadCoins creates a Coin for every object of type "coin".
+func loadCoins(m *TiledMap) []Coin {
+	var coins []Coin
+	for _, o := range m.objectsOfType("coin") {
+		coins = append(coins, Coin{x: o.X, y: o.Y})
+	}
+	return coins
+}
+
+// collectCoins marks every coin the player is touching as collected, with
+// a sparkle where each one was, and returns how many points they were worth.
+func collectCoins(coins []Coin, p *Player, particles *ParticlePool) int {
+	ts := float64(p.tileSize) // coins are one tile
+	points := 0
+	for i := range coins {
+		c := &coins[i]
+		if c.collected || !aabbOverlap(p.x, p.y, p.width, p.height, c.x, c.y, ts, ts) {
+			continue
+		}
+		c.collected = true
+		particles.Sparkle(c.x+ts/2, c.y+ts/2)
+		points += coinValue
+	}
+	return points
+}
+
+// drawCoins renders the coins that haven't been collected yet.
+func drawCoins(screen, tiles *ebiten.Image, coins []Coin, cam *Camera) {
+	for _, c := range coins {
+		if c.collected {
+			continue
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(c.x-cam.x, c.y-cam.y)
+		screen.DrawImage(spriteAt(tiles, coinSpriteIndex, cam.tileSize), op)
+	}
+}
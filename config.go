@@ -0,0 +1,60 @@
+package main
+
+// defaultTileSize is the side of the tiles in the shipped tilesheet and
+// maps: 16x16 pixels - 16bit SNES style!!
+const defaultTileSize = 16
+
+// Config holds the sizes and physics a game is built with. Start from
+// DefaultConfig and change what you need. NewGame keeps its own copy, so
+// games with different configs don't interfere.
+type Config struct {
+	TileSize     int // pixels per side of a tile, in both the tilesheet and maps
+	ScreenWidth  int // internal resolution; the window is scaled up from it
+	ScreenHeight int
+
+	// Physics is copied to the player. Its speeds are tuned for a player one
+	// tile tall and scale with the player's size.
+	Physics PhysicsConfig
+
+	// MapPath and TilesPath, when set, load the first level and the default
+	// tilesheet from disk instead of the embedded assets.
+	MapPath   string
+	TilesPath string
+
+	// DayLength is how many seconds a full day/night cycle takes. Zero
+	// turns the cycle off.
+	DayLength float64
+
+	// LightRadius is how far, in pixels, the player's light reaches on
+	// levels with a true "dark" property.
+	LightRadius float64
+
+	// CollisionTilesets names tilesets (by name, or by the base name of
+	// their .tsx source) whose tiles are solid wherever they're placed. It's
+	// for maps without a "Collision" layer; when a map has one, it wins.
+	CollisionTilesets []string
+
+	// InputDelay is how many ticks input is held back before it's applied.
+	// Zero plays with no added latency; raising it simulates netplay delay
+	// locally.
+	InputDelay int
+}
+
+// DefaultConfig returns the settings the game was designed around: 16px
+// tiles on a 160x160 screen.
+func DefaultConfig() Config {
+	return Config{
+		TileSize:     defaultTileSize,
+		ScreenWidth:  160,
+		ScreenHeight: 160,
+		Physics: PhysicsConfig{
+			ResolveOrder: ResolveLargerFirst,
+			Speed:        1.5,
+			JumpSpeed:    -5.0,
+			Gravity:      0.3,
+			MaxFall:      8.0,
+		},
+		DayLength:   180,
+		LightRadius: 48,
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+// newInputs returns the input state for every player slot, in joining
+// order. Player one has the arrows, the first gamepad, and the touchscreen
+// laid out for cfg's screen; player two has WASD and the second gamepad.
+func newInputs(cfg Config) []InputState {
+	return []InputState{
+		{Keys: playerOneKeys, Gamepad: defaultGamepadMapping, Touchscreen: true, ScreenW: cfg.ScreenWidth, ScreenH: cfg.ScreenHeight},
+		{Keys: playerTwoKeys, Gamepad: defaultGamepadMapping, Pad: 1},
+	}
+}
+
+// newInputDelays returns an empty delay buffer of frames ticks for every
+// player slot.
+func newInputDelays(n, frames int) []InputDelay {
+	delays := make([]InputDelay, n)
+	for i := range delays {
+		delays[i].frames = frames
+	}
+	return delays
+}
+
+// checkJoin adds the next player when they press jump on their own
+// controls. They appear next to player one with the same physics and full
+// health, and the lives stay shared.
+func (g *Game) checkJoin() {
+	i := len(g.players)
+	if i >= len(g.inputs) || !g.inputs[i].Frame.JumpPressed {
+		return
+	}
+	p := newPlayer(g.cfg)
+	p.physics = g.players[0].physics
+	p.x, p.y = g.players[0].x, g.players[0].y+g.players[0].height-p.height
+	g.players = append(g.players, p)
+	g.inputDelays[i] = InputDelay{frames: g.cfg.InputDelay}
+	debugf("checkJoin - Player %d joined", i+1)
+}
+
+// leashPlayer stops player i, who has just moved from (fromX, fromY),
+// from getting further from the others than the camera can show at once,
+// so Camera.frame can keep everyone on screen. A player who is already too
+// far, say after a teleport, can come back but not stray further.
+// Vertically only climbing or jumping away is stopped: a player who drops
+// below the others keeps falling, so they can still fall past killY.
+func (g *Game) leashPlayer(i int, fromX, fromY float64) {
+	if len(g.players) < 2 {
+		return
+	}
+	p := &g.players[i]
+	vw, vh := g.camera.view()
+	x0, _, x1, y1 := playerBounds(g.players, i)
+	lo, hi := min(x1-vw, fromX), max(x0+vw-p.width, fromX)
+	if x := min(max(p.x, lo), hi); x != p.x {
+		p.x, p.vx = x, 0
+	}
+	if lo = min(y1-vh, fromY); p.y < lo {
+		p.y, p.vy = lo, max(p.vy, 0)
+	}
+}
+
+// playerTouching returns the first player overlapping the w x h box at
+// (x, y), if any.
+func (g *Game) playerTouching(x, y, w, h float64) (*Player, bool) {
+	for i := range g.players {
+		p := &g.players[i]
+		if aabbOverlap(p.x, p.y, p.width, p.height, x, y, w, h) {
+			return p, true
+		}
+	}
+	return nil, false
+}
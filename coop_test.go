@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+// TestCoopPlayerFallsOffLedge walks player two off a ledge that player one
+// is standing on and checks the leash doesn't hold them up: they fall past
+// killY and the run loses a life.
+func TestCoopPlayerFallsOffLedge(t *testing.T) {
+	g := testLevel()
+	collision := Layer{Name: "Collision", Type: "tilelayer", Width: 20, Height: 10, Data: make([]int, 200)}
+	for tx := range 3 {
+		collision.Data[2*20+tx] = 1
+	}
+	g.tilemap.Layers = append(g.tilemap.Layers, collision)
+	g.tilemap.attachTiles(nil)
+	g.players = append(g.players, g.players[0])
+	g.players[0].x, g.players[0].y = 0, 16
+	g.players[1].x, g.players[1].y = 32, 16
+
+	for tick := 0; g.lives == startingLives; tick++ {
+		if tick == 300 {
+			t.Fatalf("player two at (%v, %v) after %d ticks walking off the ledge, want fallen past killY = %v",
+				g.players[1].x, g.players[1].y, tick, g.killY())
+		}
+		g.Step([]FrameInput{{}, {Right: true}})
+	}
+	if g.lives != startingLives-1 {
+		t.Errorf("lives = %d after the fall, want %d", g.lives, startingLives-1)
+	}
+}
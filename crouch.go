@@ -0,0 +1,44 @@
+package main
+
+const (
+	crouchHeightFactor = 0.5 // crouching height as a fraction of standing height
+	crouchSpeedFactor  = 0.5 // walking speed while crouched
+)
+
+// updateCrouch crouches the player while Down is held on solid ground, and
+// stands them back up once it's released, unless there's a solid tile in
+// the way overhead. Ladders are left alone since Down climbs them.
+func (p *Player) updateCrouch(collision *Layer, in FrameInput) {
+	want := in.Down && p.onGround && !p.onLadder
+	switch {
+	case want && !p.crouching:
+		p.standHeight = p.height
+		p.Resize(p.width, p.height*crouchHeightFactor)
+		p.crouching = true
+	case !want && p.crouching:
+		tall := *p
+		tall.Resize(p.width, p.standHeight)
+		if collision != nil && tall.collides(tall.x, tall.y, collision) {
+			return
+		}
+		p.stand()
+	}
+}
+
+// stand ends a crouch, restoring the player's full height.
+func (p *Player) stand() {
+	if !p.crouching {
+		return
+	}
+	p.Resize(p.width, p.standHeight)
+	p.crouching = false
+}
+
+// standingHeight is the player's height when not crouched, which movement
+// is scaled by so crouching doesn't change jump height.
+func (p *Player) standingHeight() float64 {
+	if p.crouching {
+		return p.standHeight
+	}
+	return p.height
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// The ambient tints at noon and midnight, as RGB multipliers.
+var (
+	dayTint   = [3]float32{1, 1, 1}
+	nightTint = [3]float32{0.35, 0.4, 0.7}
+)
+
+// multiplyBlend multiplies the destination by the source color, so drawing
+// a tinted pixel over the world tints it the way a ColorScale would.
+var multiplyBlend = ebiten.Blend{
+	BlendFactorSourceRGB:        ebiten.BlendFactorZero,
+	BlendFactorSourceAlpha:      ebiten.BlendFactorZero,
+	BlendFactorDestinationRGB:   ebiten.BlendFactorSourceColor,
+	BlendFactorDestinationAlpha: ebiten.BlendFactorOne,
+	BlendOperationRGB:           ebiten.BlendOperationAdd,
+	BlendOperationAlpha:         ebiten.BlendOperationAdd,
+}
+
+// ambientTint returns the tint for the current time of day. The clock
+// starts at noon and eases through midnight halfway round the cycle.
+func (g *Game) ambientTint() [3]float32 {
+	if g.dayLength <= 0 {
+		return dayTint
+	}
+	phase := float64(g.dayClock%g.dayLength) / float64(g.dayLength)
+	t := float32((math.Cos(2*math.Pi*phase) + 1) / 2)
+	var c [3]float32
+	for i := range c {
+		c[i] = nightTint[i] + (dayTint[i]-nightTint[i])*t
+	}
+	return c
+}
+
+// drawAmbientTint multiplies the drawn world by the time of day's tint.
+// Multiplying rather than laying a translucent color over it keeps every
+// sprite's own ColorScale, like the player's red, distinct at night.
+func (g *Game) drawAmbientTint(world *ebiten.Image) {
+	c := g.ambientTint()
+	if c == dayTint {
+		return
+	}
+	b := world.Bounds()
+	op := &ebiten.DrawImageOptions{Blend: multiplyBlend}
+	op.GeoM.Scale(float64(b.Dx()), float64(b.Dy()))
+	op.ColorScale.Scale(c[0], c[1], c[2], 1)
+	world.DrawImage(whitePixel(), op)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var (
+	debugSolidColor    = color.RGBA{0xff, 0x00, 0x00, 0x60}
+	debugLadderColor   = color.RGBA{0x00, 0x80, 0xff, 0x60}
+	debugPlayerColor   = color.RGBA{0x00, 0xff, 0x00, 0xff}
+	debugVelocityColor = color.RGBA{0xff, 0xff, 0x00, 0xff}
+)
+
+// debugVelocityScale stretches the velocity line so it's long enough to read.
+const debugVelocityScale = 4
+
+// drawDebugOverlay shades every on-screen collision and ladder tile and
+// outlines each player's bounding box with a line showing their velocity.
+func (g *Game) drawDebugOverlay(screen *ebiten.Image) {
+	drawDebugTiles(screen, g.tilemap.collisionLayer(), &g.camera, debugSolidColor)
+	drawDebugTiles(screen, getLadderLayer(g.tilemap.Layers), &g.camera, debugLadderColor)
+
+	for _, p := range g.players {
+		x, y := float32(p.x-g.camera.x), float32(p.y-g.camera.y)
+		vector.StrokeRect(screen, x, y, float32(p.width), float32(p.height), 1, debugPlayerColor, false)
+		cx, cy := x+float32(p.width/2), y+float32(p.height/2)
+		vector.StrokeLine(screen, cx, cy, cx+float32(p.vx*debugVelocityScale), cy+float32(p.vy*debugVelocityScale), 1, debugVelocityColor, false)
+	}
+}
+
+// debugHUDFirstLine is the HUD line the debug readout starts on, below the
+// score, lives, and keys.
+const debugHUDFirstLine = 3
+
+// drawDebugHUD prints the tick rate, each player's position, velocity, and
+// contact flags, and the tuned physics down the left of the screen. With
+// more than one player each block is labelled with whose it is.
+func (g *Game) drawDebugHUD(screen *ebiten.Image) {
+	lines := []string{fmt.Sprintf("TPS %.1f", ebiten.ActualTPS())}
+	for i := range g.players {
+		p := &g.players[i]
+		if len(g.players) > 1 {
+			lines = append(lines, fmt.Sprintf("P%d", i+1))
+		}
+		lines = append(lines,
+			fmt.Sprintf("X %.1f Y %.1f", p.x, p.y),
+			fmt.Sprintf("VX %.2f VY %.2f", p.vx, p.vy),
+			fmt.Sprintf("GROUND %v LADDER %v", p.onGround, p.onLadder),
+		)
+	}
+	physics := g.players[0].physics
+	lines = append(lines, fmt.Sprintf("GRAV %.2f JUMP %.2f", physics.Gravity, physics.JumpSpeed))
+	anchor := HUDAnchor{Anchor: TopLeft, OffsetX: 2}
+	for i, l := range lines {
+		drawHUDText(screen, l, anchor, debugHUDFirstLine+i)
+	}
+}
+
+// drawDebugTiles fills every non-empty on-screen tile of l with clr.
+func drawDebugTiles(screen *ebiten.Image, l *Layer, cam *Camera, clr color.Color) {
+	if l == nil {
+		return
+	}
+	x0, y0, x1, y1 := cam.visibleTiles(l.Width, l.Height)
+	for ty := y0; ty < y1; ty++ {
+		for tx := x0; tx < x1; tx++ {
+			if l.tileAt(tx, ty) == 0 {
+				continue
+			}
+			ts := cam.tileSize
+			vector.DrawFilledRect(screen, float32(float64(tx*ts)-cam.x), float32(float64(ty*ts)-cam.y), float32(ts), float32(ts), clr, false)
+		}
+	}
+}
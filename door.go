@@ -0,0 +1,165 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	keySpriteIndex  = 96
+	doorSpriteIndex = 56
+)
+
+// keyColors tints keys and doors by their "color" property. Any other
+// color, including none, is drawn untinted.
+var keyColors = map[string][3]float32{
+	"red":    {1, 0.3, 0.3},
+	"green":  {0.3, 1, 0.3},
+	"blue":   {0.4, 0.5, 1},
+	"yellow": {1, 1, 0.3},
+}
+
+// Key is a collectible that unlocks every door of its color.
+type Key struct {
+	x, y      float64
+	color     string
+	collected bool
+}
+
+// Door blocks the way until the player reaches it holding a key of the
+// same color. While locked the cells it covers are blocked, whatever tiles
+// the Collision layer has there.
+type Door struct {
+	obj            Object
+	color          string
+	open           bool
+	x0, y0, x1, y1 int // the tiles it covers, inclusive
+}
+
+// loadKeys creates a Key for every object of type "key".
+func loadKeys(m *TiledMap) []Key {
+	var keys []Key
+	for _, o := range m.objectsOfType("key") {
+		keys = append(keys, Key{x: o.X, y: o.Y, color: o.Properties.String("color")})
+	}
+	return keys
+}
+
+// loadDoors creates a Door for every object of type "door" and makes the
+// tiles it covers solid. A door smaller than a tile still covers one; tiles
+// are ts pixels on a side.
+func loadDoors(m *TiledMap, ts float64) []Door {
+	var doors []Door
+	for _, o := range m.objectsOfType("door") {
+		d := Door{obj: o, color: o.Properties.String("color")}
+		d.x0, d.x1 = tileSpan(o.X, o.Width, ts)
+		d.y0, d.y1 = tileSpan(o.Y, o.Height, ts)
+		d.x1, d.y1 = max(d.x1, d.x0), max(d.y1, d.y0)
+		d.setSolid(m.tiles, true)
+		doors = append(doors, d)
+	}
+	return doors
+}
+
+// setSolid blocks or unblocks every cell the door covers.
+func (d *Door) setSolid(t *levelTiles, solid bool) {
+	for ty := d.y0; ty <= d.y1; ty++ {
+		for tx := d.x0; tx <= d.x1; tx++ {
+			t.setBlocked(tx, ty, solid)
+		}
+	}
+}
+
+// openDoor unlocks door i for good.
+func (g *Game) openDoor(i int) {
+	d := &g.doors[i]
+	if d.open {
+		return
+	}
+	d.open = true
+	d.setSolid(g.tilemap.tiles, false)
+	debugf("openDoor - Opened %q door %q", d.color, d.obj.Name)
+}
+
+// checkKeysAndDoors picks up any key a player touches and opens any
+// locked door one walks up against while its key is held. Keys are shared
+// between players.
+func (g *Game) checkKeysAndDoors() {
+	ts := float64(g.cfg.TileSize)
+	for i := range g.keys {
+		k := &g.keys[i]
+		if k.collected {
+			continue
+		}
+		if _, ok := g.playerTouching(k.x, k.y, ts, ts); !ok {
+			continue
+		}
+		k.collected = true
+		g.heldKeys[k.color] = true
+		g.sound.Play(SoundCoin)
+	}
+	for i := range g.doors {
+		d := &g.doors[i]
+		if d.open || !g.heldKeys[d.color] {
+			continue
+		}
+		// The door is solid, so reach one pixel past its edges.
+		x, y := float64(d.x0)*ts-1, float64(d.y0)*ts-1
+		w, h := float64(d.x1-d.x0+1)*ts+2, float64(d.y1-d.y0+1)*ts+2
+		if _, ok := g.playerTouching(x, y, w, h); ok {
+			g.openDoor(i)
+		}
+	}
+}
+
+// keyLabel lists the colors of the keys the player holds, for the HUD.
+func (g *Game) keyLabel() string {
+	var colors []string
+	for c := range g.heldKeys {
+		if c == "" {
+			c = "key"
+		}
+		colors = append(colors, c)
+	}
+	slices.Sort(colors)
+	return strings.ToUpper(strings.Join(colors, " "))
+}
+
+// tintKey applies the tint for color to op.
+func tintKey(op *ebiten.DrawImageOptions, color string) {
+	if c, ok := keyColors[color]; ok {
+		op.ColorScale.Scale(c[0], c[1], c[2], 1)
+	}
+}
+
+// drawKeys renders the keys that haven't been picked up yet.
+func drawKeys(screen, tiles *ebiten.Image, keys []Key, cam *Camera) {
+	for _, k := range keys {
+		if k.collected {
+			continue
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(k.x-cam.x, k.y-cam.y)
+		tintKey(op, k.color)
+		screen.DrawImage(spriteAt(tiles, keySpriteIndex, cam.tileSize), op)
+	}
+}
+
+// drawDoors renders every locked door, one sprite per tile it covers.
+func drawDoors(screen, tiles *ebiten.Image, doors []Door, cam *Camera) {
+	for _, d := range doors {
+		if d.open {
+			continue
+		}
+		for ty := d.y0; ty <= d.y1; ty++ {
+			for tx := d.x0; tx <= d.x1; tx++ {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(float64(tx*cam.tileSize)-cam.x, float64(ty*cam.tileSize)-cam.y)
+				tintKey(op, d.color)
+				screen.DrawImage(spriteAt(tiles, doorSpriteIndex, cam.tileSize), op)
+			}
+		}
+	}
+}
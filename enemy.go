@@ -0,0 +1,154 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+const (
+	enemySpriteIndex = 320
+	enemySpeed       = 0.5  // pixels per reference tick
+	enemyPatrolRange = 48.0 // default distance either side of the spawn point
+	enemyDamage      = 1
+	enemyStompBounce = -3.5
+	enemyStompLeeway = 4.0 // how far below the enemy's top the player's feet can be and still stomp
+	enemyKnockbackX  = 2.0
+	enemyKnockbackY  = -2.0
+)
+
+// Enemy walks back and forth along the ground, turning at walls, ledges,
+// and the ends of its patrol range. Touching it hurts; landing on it
+// defeats it.
+type Enemy struct {
+	x, y       float64
+	vx, vy     float64
+	minX, maxX float64 // patrol range for the enemy's left edge
+}
+
+// loadEnemies creates an Enemy for every object of type "enemy". An object
+// wider than a tile (ts pixels) patrols its own width; otherwise the "range"
+// property (pixels either side of the spawn point) sets how far it wanders.
+func loadEnemies(m *TiledMap, ts float64) []Enemy {
+	var enemies []Enemy
+	for _, o := range m.objectsOfType("enemy") {
+		e := Enemy{x: o.X, y: o.Y, vx: enemySpeed}
+		if o.Width > ts {
+			e.minX, e.maxX = o.X, o.X+o.Width-ts
+		} else {
+			r := o.Properties.Float("range", enemyPatrolRange)
+			e.minX, e.maxX = o.X-r, o.X+r
+		}
+		enemies = append(enemies, e)
+	}
+	return enemies
+}
+
+// boxSolid reports whether a w x h box at (x, y) overlaps any solid tile
+// of a layer whose tiles are ts pixels on a side. Like collides, the right
+// and bottom edges are exclusive.
+func boxSolid(collision *Layer, ts, x, y, w, h float64) bool {
+	left, right := tileSpan(x, w, ts)
+	top, bottom := tileSpan(y, h, ts)
+	for ty := top; ty <= bottom; ty++ {
+		for tx := left; tx <= right; tx++ {
+			if collision.solidAt(tx, ty) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Update moves the enemy one tick. Off the ground it falls under gravity, no
+// faster than maxFall; on the ground it walks, turning around instead of
+// walking into a wall, off a ledge, or out of its patrol range. Enemies are
+// one tile, ts pixels, on a side.
+func (e *Enemy) Update(collision *Layer, ts, gravity, maxFall, k float64) {
+	if collision != nil {
+		e.vy = min(e.vy+gravity*k, maxFall)
+		newY := e.y + e.vy*k
+		if !boxSolid(collision, ts, e.x, newY, ts, ts) {
+			e.y = newY
+			return
+		}
+		e.y = sweep(e.y, newY, func(y float64) bool { return !boxSolid(collision, ts, e.x, y, ts, ts) })
+		e.vy = 0
+	}
+	nx := e.x + e.vx*k
+	blocked := nx < e.minX || nx > e.maxX
+	if collision != nil && !blocked {
+		// The ledge test looks one pixel below the enemy's leading edge.
+		front := nx + ts - 1
+		if e.vx < 0 {
+			front = nx
+		}
+		wall := boxSolid(collision, ts, nx, e.y, ts, ts)
+		ledge := !boxSolid(collision, ts, front, e.y+ts, 1, 1)
+		blocked = wall || ledge
+	}
+	if blocked {
+		e.vx = -e.vx
+		return
+	}
+	e.x = nx
+}
+
+// updateEnemies moves every enemy and resolves contact with the players:
+// falling onto an enemy defeats it and bounces the player, any other touch
+// hurts the player. Enemies that fall off the bottom of the map are gone.
+func (g *Game) updateEnemies(collision *Layer, gravity, maxFall, k float64) {
+	ts := float64(g.cfg.TileSize)
+	_, mapH := g.mapSize()
+	alive := g.enemies[:0]
+	for _, e := range g.enemies {
+		e.Update(collision, ts, gravity, maxFall, k)
+		if e.y > mapH {
+			continue
+		}
+		stomped := false
+		for i := range g.players {
+			if stomped = g.players[i].touchEnemy(&e, k); stomped {
+				break
+			}
+		}
+		if !stomped {
+			alive = append(alive, e)
+		}
+	}
+	g.enemies = alive
+}
+
+// touchEnemy resolves p's contact with e, reporting whether p stomped it.
+func (p *Player) touchEnemy(e *Enemy, k float64) bool {
+	ts := float64(p.tileSize)
+	if p.noClip || !aabbOverlap(p.x, p.y, p.width, p.height, e.x, e.y, ts, ts) {
+		return false
+	}
+	if p.vy > 0 && p.y+p.height-p.vy*k <= e.y+enemyStompLeeway {
+		p.vy = enemyStompBounce
+		p.onGround = false
+		p.isJumping = false
+		debugf("updateEnemies - Stomped enemy")
+		return true
+	}
+	before := p.health
+	p.TakeDamage(enemyDamage)
+	if p.health < before {
+		dir := 1.0
+		if p.x+p.width/2 < e.x+ts/2 {
+			dir = -1
+		}
+		p.vx, p.vy = enemyKnockbackX*dir, enemyKnockbackY
+		p.knockbackTimer = knockbackTicks
+		p.onGround, p.onLadder, p.onRope = false, false, false
+	}
+	return false
+}
+
+// Draw renders the enemy facing the way it's walking.
+func (e *Enemy) Draw(screen, tiles *ebiten.Image, cam *Camera) {
+	op := &ebiten.DrawImageOptions{}
+	if e.vx < 0 {
+		op.GeoM.Scale(-1, 1)
+		op.GeoM.Translate(float64(cam.tileSize), 0)
+	}
+	op.GeoM.Translate(e.x-cam.x, e.y-cam.y)
+	screen.DrawImage(spriteAt(tiles, enemySpriteIndex, cam.tileSize), op)
+}
@@ -0,0 +1,91 @@
+package main
+
+import "testing"
+
+// TestEnemyFallsThenPatrols drops an enemy from the air and checks it lands
+// on the floor and then walks, turning at the walls.
+func TestEnemyFallsThenPatrols(t *testing.T) {
+	collision := testLayer(
+		"#......#",
+		"#......#",
+		"#......#",
+		"########",
+	)
+	physics := DefaultConfig().Physics
+	e := Enemy{x: 32, y: 0, vx: enemySpeed, minX: 0, maxX: 128}
+	for range 60 {
+		e.Update(collision, 16, physics.Gravity, physics.MaxFall, 1)
+	}
+	if e.y != 32 || e.vy != 0 {
+		t.Fatalf("enemy at y = %v falling at %v, want standing on the floor at y = 32", e.y, e.vy)
+	}
+	minX, maxX := e.x, e.x
+	for range 600 {
+		e.Update(collision, 16, physics.Gravity, physics.MaxFall, 1)
+		minX, maxX = min(minX, e.x), max(maxX, e.x)
+	}
+	if minX < 16 || maxX > 96 {
+		t.Errorf("enemy walked from x = %v to %v, want it kept between the walls (16 to 96)", minX, maxX)
+	}
+	if minX > 20 || maxX < 92 {
+		t.Errorf("enemy only walked from x = %v to %v, want it to patrol wall to wall", minX, maxX)
+	}
+}
+
+// TestEnemyTurnsAtLedge checks an enemy on a short platform turns at its
+// edges instead of walking off.
+func TestEnemyTurnsAtLedge(t *testing.T) {
+	collision := testLayer(
+		"........",
+		"..###...",
+		"........",
+	)
+	physics := DefaultConfig().Physics
+	e := Enemy{x: 32, y: 0, vx: enemySpeed, minX: 0, maxX: 128}
+	for range 600 {
+		e.Update(collision, 16, physics.Gravity, physics.MaxFall, 1)
+		if e.x < 32-enemySpeed || e.x > 64+enemySpeed || e.y != 0 {
+			t.Fatalf("enemy at (%v, %v), want it kept on the platform", e.x, e.y)
+		}
+	}
+}
+
+// TestSpawnedEnemyPatrols fires a "spawn enemy" trigger placed up in the air
+// and checks the enemy drops to the floor and walks rather than hovering.
+func TestSpawnedEnemyPatrols(t *testing.T) {
+	g := testLevel()
+	floor := make([]string, 10)
+	for i := range floor {
+		floor[i] = "...................."
+	}
+	floor[9] = "####################"
+	collision := testLayer(floor...)
+	g.tilemap.Layers = append(g.tilemap.Layers, *collision)
+	// An unnamed object mustn't be mistaken for the trigger's missing target.
+	g.tilemap.Layers[0].Objects = append(g.tilemap.Layers[0].Objects, Object{Type: "coin", X: 288, Y: 16})
+	g.triggers = []Trigger{{
+		obj:   Object{Name: "Ambush", Type: "trigger", X: 128, Y: 32, Width: 32, Height: 32},
+		event: "spawn enemy",
+	}}
+	p := &g.players[0]
+	p.x, p.y = 136, 40
+	g.checkTriggers()
+	if len(g.enemies) != 1 {
+		t.Fatalf("%d enemies after the trigger fired, want 1", len(g.enemies))
+	}
+	p.x, p.y = 16, 128
+
+	physics := DefaultConfig().Physics
+	minX, maxX := g.enemies[0].x, g.enemies[0].x
+	for range 300 {
+		g.updateEnemies(g.tilemap.collisionLayer(), physics.Gravity, physics.MaxFall, 1)
+		e := g.enemies[0]
+		minX, maxX = min(minX, e.x), max(maxX, e.x)
+	}
+	if e := g.enemies[0]; e.y != 128 {
+		t.Errorf("spawned enemy at y = %v, want on the floor at y = 128", e.y)
+	}
+	if maxX-minX < enemyPatrolRange {
+		t.Errorf("spawned enemy only walked from x = %v to %v, want it patrolling", minX, maxX)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+const (
+	hazardDamage     = 1
+	hazardKnockbackX = 2.5
+	hazardKnockbackY = -3.0
+	knockbackTicks   = 12
+)
+
+// touchingHazard returns the tile coordinate of a hazard tile the player's
+// bounding box overlaps, if any. Hazards never block movement.
+func (p *Player) touchingHazard(hazards *Layer) (int, int, bool) {
+	if hazards == nil {
+		return 0, 0, false
+	}
+	leftTile, rightTile := tileSpan(p.x, p.width, float64(p.tileSize))
+	topTile, bottomTile := tileSpan(p.y, p.height, float64(p.tileSize))
+	for ty := topTile; ty <= bottomTile; ty++ {
+		for tx := leftTile; tx <= rightTile; tx++ {
+			if hazards.tileAt(tx, ty) != 0 {
+				return tx, ty, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// checkHazards hurts the player if they're touching a hazard tile and knocks
+// them up and away from it.
+func (p *Player) checkHazards(hazards *Layer) {
+	tx, ty, ok := p.touchingHazard(hazards)
+	if !ok || p.noClip {
+		return
+	}
+	before := p.health
+	p.TakeDamage(hazardDamage)
+	if p.health == before {
+		return // invulnerable
+	}
+	dir := 1.0
+	if p.x+p.width/2 < float64(tx*p.tileSize)+float64(p.tileSize)/2 {
+		dir = -1
+	}
+	p.vx = hazardKnockbackX * dir
+	p.vy = hazardKnockbackY
+	p.knockbackTimer = knockbackTicks
+	p.onGround = false
+	p.onLadder = false
+	p.onRope = false
+	debugf("checkHazards - Hurt by hazard at (%d, %d), health: %d", tx, ty, p.health)
+}
@@ -0,0 +1,53 @@
+package main
+
+import "testing"
+
+func TestTouchingHazard(t *testing.T) {
+	hazards := testLayer(
+		"....",
+		"..^.",
+	)
+	tests := []struct {
+		name string
+		x, y float64
+		want bool
+	}{
+		{"standing on the spike", 32, 16, true},
+		{"overlapping its left edge", 16.5, 16, true},
+		{"overlapping its right edge", 47.5, 16, true},
+		{"just above it", 32, 0, false},
+		{"flush against its left side", 16, 16, false},
+		{"flush against its right side", 48, 16, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPlayer(tt.x, tt.y)
+			tx, ty, got := p.touchingHazard(hazards)
+			if got != tt.want {
+				t.Fatalf("touchingHazard at (%v, %v) = %v, want %v", tt.x, tt.y, got, tt.want)
+			}
+			if got && (tx != 2 || ty != 1) {
+				t.Errorf("touchingHazard reported tile (%d, %d), want the spike at (2, 1)", tx, ty)
+			}
+		})
+	}
+}
+
+func TestCheckHazardsKnocksAway(t *testing.T) {
+	hazards := testLayer("..^.")
+	p := testPlayer(24, 0) // center left of the spike's
+	p.checkHazards(hazards)
+	if p.health != playerMaxHealth-hazardDamage {
+		t.Errorf("health = %d, want %d", p.health, playerMaxHealth-hazardDamage)
+	}
+	if p.vx >= 0 || p.vy >= 0 {
+		t.Errorf("knockback velocity (%v, %v), want up and to the left", p.vx, p.vy)
+	}
+
+	p = testPlayer(32, 0)
+	p.noClip = true
+	p.checkHazards(hazards)
+	if p.health != playerMaxHealth {
+		t.Error("a no-clip player was hurt by a spike")
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// The HUD uses ebitenutil's debug font, which stays crisp at the internal
+// resolution.
+const (
+	hudLineHeight = 16
+	hudCharWidth  = 6
+)
+
+// Anchor names a point on the screen that a HUD element is positioned from.
+type Anchor int
+
+const (
+	TopLeft Anchor = iota
+	TopCenter
+	TopRight
+	CenterLeft
+	Center
+	CenterRight
+	BottomLeft
+	BottomCenter
+	BottomRight
+)
+
+// HUDAnchor places a HUD element relative to a screen corner or edge instead
+// of at a hardcoded coordinate, so it stays put when the resolution changes.
+// The offset is a margin measured inward from the anchored edge; on centered
+// axes it is added as-is.
+type HUDAnchor struct {
+	Anchor           Anchor
+	OffsetX, OffsetY float64
+}
+
+// Position returns the top-left corner for an element of size w x h on a
+// screen of screenW x screenH. Pass the dimensions returned by Layout (or
+// screen.Bounds() in Draw) so the result tracks the current resolution.
+func (a HUDAnchor) Position(w, h float64, screenW, screenH int) (x, y float64) {
+	sw, sh := float64(screenW), float64(screenH)
+
+	switch a.Anchor {
+	case TopLeft, CenterLeft, BottomLeft:
+		x = a.OffsetX
+	case TopCenter, Center, BottomCenter:
+		x = (sw-w)/2 + a.OffsetX
+	case TopRight, CenterRight, BottomRight:
+		x = sw - w - a.OffsetX
+	}
+
+	switch a.Anchor {
+	case TopLeft, TopCenter, TopRight:
+		y = a.OffsetY
+	case CenterLeft, Center, CenterRight:
+		y = (sh-h)/2 + a.OffsetY
+	case BottomLeft, BottomCenter, BottomRight:
+		y = sh - h - a.OffsetY
+	}
+	return x, y
+}
+
+// drawHUDText prints label at anchor, line lines down from it.
+func drawHUDText(screen *ebiten.Image, label string, anchor HUDAnchor, line int) {
+	x, y := anchor.Position(float64(len(label)*hudCharWidth), hudLineHeight, screen.Bounds().Dx(), screen.Bounds().Dy())
+	ebitenutil.DebugPrintAt(screen, label, int(x), int(y)+line*hudLineHeight)
+}
+
+// drawHUD shows the score, lives, and any keys held in the top-left corner,
+// the level's countdown at the top if it has one, and the time spent in the
+// current run in the top-right. It's drawn in screen space so it doesn't
+// scroll with the camera.
+func (g *Game) drawHUD(screen *ebiten.Image) {
+	left := HUDAnchor{Anchor: TopLeft, OffsetX: 2}
+	drawHUDText(screen, fmt.Sprintf("SCORE %d", g.score), left, 0)
+	drawHUDText(screen, fmt.Sprintf("LIVES %d", g.lives), left, 1)
+	if len(g.heldKeys) > 0 {
+		drawHUDText(screen, g.keyLabel(), left, 2)
+	}
+
+	if g.levelTime() > 0 {
+		remaining := (g.timeLeft + ebiten.DefaultTPS - 1) / ebiten.DefaultTPS
+		drawHUDText(screen, fmt.Sprintf("TIME %d", remaining), HUDAnchor{Anchor: TopCenter}, 1)
+	}
+
+	seconds := g.ticks / ebiten.DefaultTPS
+	drawHUDText(screen, fmt.Sprintf("%d:%02d", seconds/60, seconds%60), HUDAnchor{Anchor: TopRight, OffsetX: 2}, 0)
+}
+
+// dimScreen darkens the frame underneath an overlay.
+func dimScreen(screen *ebiten.Image) {
+	b := screen.Bounds()
+	vector.DrawFilledRect(screen, 0, 0, float32(b.Dx()), float32(b.Dy()), color.RGBA{0, 0, 0, 0xa0}, false)
+}
+
+// drawPauseOverlay dims the frame underneath and labels it as paused.
+func drawPauseOverlay(screen *ebiten.Image) {
+	dimScreen(screen)
+	drawHUDText(screen, "PAUSED", HUDAnchor{Anchor: Center}, 0)
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestHUDAnchorPosition(t *testing.T) {
+	// A 20x10 element, 2px in from the anchored edges, on two resolutions.
+	tests := []struct {
+		anchor       Anchor
+		small, large [2]float64
+	}{
+		{TopLeft, [2]float64{2, 2}, [2]float64{2, 2}},
+		{TopCenter, [2]float64{152, 2}, [2]float64{312, 2}},
+		{TopRight, [2]float64{298, 2}, [2]float64{618, 2}},
+		{CenterLeft, [2]float64{2, 117}, [2]float64{2, 177}},
+		{Center, [2]float64{152, 117}, [2]float64{312, 177}},
+		{CenterRight, [2]float64{298, 117}, [2]float64{618, 177}},
+		{BottomLeft, [2]float64{2, 228}, [2]float64{2, 348}},
+		{BottomCenter, [2]float64{152, 228}, [2]float64{312, 348}},
+		{BottomRight, [2]float64{298, 228}, [2]float64{618, 348}},
+	}
+	for _, tt := range tests {
+		a := HUDAnchor{Anchor: tt.anchor, OffsetX: 2, OffsetY: 2}
+		for _, s := range []struct {
+			w, h int
+			want [2]float64
+		}{{320, 240, tt.small}, {640, 360, tt.large}} {
+			if x, y := a.Position(20, 10, s.w, s.h); x != s.want[0] || y != s.want[1] {
+				t.Errorf("anchor %d on %dx%d at (%v, %v), want (%v, %v)", tt.anchor, s.w, s.h, x, y, s.want[0], s.want[1])
+			}
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// InputDevice is the kind of device input came from.
+type InputDevice int
+
+const (
+	DeviceKeyboard InputDevice = iota
+	DeviceGamepad
+)
+
+// InputAction is something the player can do, used to look up button
+// prompts for the current device.
+type InputAction int
+
+const (
+	ActionJump InputAction = iota
+	ActionAttack
+	ActionClimb
+	ActionDash
+)
+
+// prompts holds the label to show for each action on each device.
+var prompts = map[InputDevice]map[InputAction]string{
+	DeviceKeyboard: {
+		ActionJump:   "Space",
+		ActionAttack: "X",
+		ActionClimb:  "Up",
+		ActionDash:   "Shift",
+	},
+	DeviceGamepad: {
+		ActionJump:   "(A)",
+		ActionAttack: "(X)",
+		ActionClimb:  "D-Pad Up",
+		ActionDash:   "(B)",
+	},
+}
+
+// FrameInput is everything the player asked for on one tick. It's a plain
+// value so it can be buffered, delayed, or replayed.
+type FrameInput struct {
+	Left, Right, Up, Down bool
+	Jump                  bool // jump is held
+	JumpPressed           bool // jump was pressed this tick
+	JumpReleased          bool // jump was let go this tick
+	AttackPressed         bool
+	DashPressed           bool
+}
+
+// GamepadMapping says which standard-layout gamepad controls drive which
+// actions. The D-pad and the left stick always move; the stick only counts
+// once it's pushed past Deadzone.
+type GamepadMapping struct {
+	Jump     ebiten.StandardGamepadButton
+	Attack   ebiten.StandardGamepadButton
+	Dash     ebiten.StandardGamepadButton
+	Deadzone float64
+}
+
+// defaultGamepadMapping puts jump on the bottom face button (A on an Xbox
+// pad), attack on the left one (X), and dash on the right one (B).
+var defaultGamepadMapping = GamepadMapping{
+	Jump:     ebiten.StandardGamepadButtonRightBottom,
+	Attack:   ebiten.StandardGamepadButtonRightLeft,
+	Dash:     ebiten.StandardGamepadButtonRightRight,
+	Deadzone: 0.3,
+}
+
+// KeyMapping says which keyboard keys drive which actions for one player.
+type KeyMapping struct {
+	Left, Right, Up, Down ebiten.Key
+	Jump, Attack          ebiten.Key
+	Dash                  []ebiten.Key // any of these dashes
+}
+
+// has reports whether k is one of the mapping's keys.
+func (km KeyMapping) has(k ebiten.Key) bool {
+	switch k {
+	case km.Left, km.Right, km.Up, km.Down, km.Jump, km.Attack:
+		return true
+	}
+	return slices.Contains(km.Dash, k)
+}
+
+// playerOneKeys moves with the arrows, jumps with Space, attacks with X, and
+// dashes with either Shift.
+var playerOneKeys = KeyMapping{
+	Left: ebiten.KeyLeft, Right: ebiten.KeyRight, Up: ebiten.KeyUp, Down: ebiten.KeyDown,
+	Jump: ebiten.KeySpace, Attack: ebiten.KeyX,
+	Dash: []ebiten.Key{ebiten.KeyShiftLeft, ebiten.KeyShiftRight},
+}
+
+// playerTwoKeys moves with WASD, jumps with E, attacks with Q, and dashes
+// with C, all under the left hand.
+var playerTwoKeys = KeyMapping{
+	Left: ebiten.KeyA, Right: ebiten.KeyD, Up: ebiten.KeyW, Down: ebiten.KeyS,
+	Jump: ebiten.KeyE, Attack: ebiten.KeyQ,
+	Dash: []ebiten.Key{ebiten.KeyC},
+}
+
+// merge combines two inputs, e.g. keyboard and gamepad, so either can
+// drive each control.
+func (f FrameInput) merge(o FrameInput) FrameInput {
+	return FrameInput{
+		Left:          f.Left || o.Left,
+		Right:         f.Right || o.Right,
+		Up:            f.Up || o.Up,
+		Down:          f.Down || o.Down,
+		Jump:          f.Jump || o.Jump,
+		JumpPressed:   f.JumpPressed || o.JumpPressed,
+		JumpReleased:  f.JumpReleased || o.JumpReleased,
+		AttackPressed: f.AttackPressed || o.AttackPressed,
+		DashPressed:   f.DashPressed || o.DashPressed,
+	}
+}
+
+// InputDelay is a FIFO of FrameInputs that releases each one a fixed number
+// of frames after it was pushed.
+type InputDelay struct {
+	frames int
+	queue  []FrameInput
+}
+
+// Push queues in and returns the input to apply this tick: the one pushed
+// d.frames ticks ago, or an empty input while the buffer is still filling.
+func (d *InputDelay) Push(in FrameInput) FrameInput {
+	d.queue = append(d.queue, in)
+	if len(d.queue) <= d.frames {
+		return FrameInput{}
+	}
+	out := d.queue[0]
+	d.queue = append(d.queue[:0], d.queue[1:]...)
+	return out
+}
+
+// InputState tracks one player's input for the current tick.
+type InputState struct {
+	// Frame is this tick's input, before any delay is applied.
+	Frame FrameInput
+
+	// Device is whichever device produced the most recent press, so button
+	// prompts match what the player is actually holding.
+	Device InputDevice
+
+	// Keys maps this player's keyboard keys to actions.
+	Keys KeyMapping
+
+	// Gamepad maps the buttons of this player's gamepad to actions. Pad
+	// picks which connected gamepad that is, in connection order.
+	Gamepad GamepadMapping
+	Pad     int
+
+	// Touchscreen reads the on-screen buttons too, laid out on a screen of
+	// ScreenW x ScreenH internal pixels. Only one player should have it.
+	Touchscreen      bool
+	ScreenW, ScreenH int
+
+	// PausePressed is set on the tick Escape or the player's gamepad's
+	// Start button is pressed. It's kept out of Frame because pausing isn't part of the
+	// simulation.
+	PausePressed bool
+
+	// Touch is set once a touchscreen has been used, so the on-screen
+	// buttons are only shown on touch devices.
+	Touch bool
+
+	keys      []ebiten.Key
+	gamepads  []ebiten.GamepadID
+	gpButtons []ebiten.StandardGamepadButton
+	touchIDs  []ebiten.TouchID
+	touchHeld [touchControlCount]bool
+}
+
+// Update reads this tick's input. It should run once at the start of every
+// Game.Update.
+func (in *InputState) Update() {
+	in.Frame = readKeyboard(in.Keys)
+	if in.Touchscreen {
+		in.Frame = in.Frame.merge(in.readTouch())
+	}
+	in.PausePressed = inpututil.IsKeyJustPressed(ebiten.KeyEscape)
+
+	// Only this player's own keys count, so another player sharing the
+	// keyboard doesn't switch this one's prompts.
+	in.keys = inpututil.AppendJustPressedKeys(in.keys[:0])
+	keyPressed := slices.ContainsFunc(in.keys, in.Keys.has)
+
+	gamepadPressed := false
+	in.gamepads = ebiten.AppendGamepadIDs(in.gamepads[:0])
+	if in.Pad < len(in.gamepads) {
+		id := in.gamepads[in.Pad]
+		in.Frame = in.Frame.merge(readGamepad(id, in.Gamepad))
+		if inpututil.IsStandardGamepadButtonJustPressed(id, ebiten.StandardGamepadButtonCenterRight) {
+			in.PausePressed = true
+		}
+		in.gpButtons = inpututil.AppendJustPressedStandardGamepadButtons(id, in.gpButtons[:0])
+		gamepadPressed = len(in.gpButtons) > 0
+	}
+
+	in.Device = lastDevice(in.Device, keyPressed, gamepadPressed)
+}
+
+// readKeyboard samples the keyboard controls in km.
+func readKeyboard(km KeyMapping) FrameInput {
+	dash := false
+	for _, k := range km.Dash {
+		dash = dash || inpututil.IsKeyJustPressed(k)
+	}
+	return FrameInput{
+		Left:          ebiten.IsKeyPressed(km.Left),
+		Right:         ebiten.IsKeyPressed(km.Right),
+		Up:            ebiten.IsKeyPressed(km.Up),
+		Down:          ebiten.IsKeyPressed(km.Down),
+		Jump:          ebiten.IsKeyPressed(km.Jump),
+		JumpPressed:   inpututil.IsKeyJustPressed(km.Jump),
+		JumpReleased:  inpututil.IsKeyJustReleased(km.Jump),
+		AttackPressed: inpututil.IsKeyJustPressed(km.Attack),
+		DashPressed:   dash,
+	}
+}
+
+// readGamepad samples a standard-layout gamepad: the D-pad or left stick
+// moves and climbs, and the mapped buttons jump and attack. Gamepads without
+// a standard mapping report no input.
+func readGamepad(id ebiten.GamepadID, m GamepadMapping) FrameInput {
+	if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+		return FrameInput{}
+	}
+	x := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+	y := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+	pressed := func(b ebiten.StandardGamepadButton) bool {
+		return ebiten.IsStandardGamepadButtonPressed(id, b)
+	}
+	return FrameInput{
+		Left:          pressed(ebiten.StandardGamepadButtonLeftLeft) || x < -m.Deadzone,
+		Right:         pressed(ebiten.StandardGamepadButtonLeftRight) || x > m.Deadzone,
+		Up:            pressed(ebiten.StandardGamepadButtonLeftTop) || y < -m.Deadzone,
+		Down:          pressed(ebiten.StandardGamepadButtonLeftBottom) || y > m.Deadzone,
+		Jump:          pressed(m.Jump),
+		JumpPressed:   inpututil.IsStandardGamepadButtonJustPressed(id, m.Jump),
+		JumpReleased:  inpututil.IsStandardGamepadButtonJustReleased(id, m.Jump),
+		AttackPressed: inpututil.IsStandardGamepadButtonJustPressed(id, m.Attack),
+		DashPressed:   inpututil.IsStandardGamepadButtonJustPressed(id, m.Dash),
+	}
+}
+
+// lastDevice returns the device to report after a tick in which the keyboard
+// and/or a gamepad had a fresh press. If both did, the gamepad wins, since a
+// stray key press is the likelier accident. With no presses the previous
+// device sticks.
+func lastDevice(prev InputDevice, keyPressed, gamepadPressed bool) InputDevice {
+	switch {
+	case gamepadPressed:
+		return DeviceGamepad
+	case keyPressed:
+		return DeviceKeyboard
+	}
+	return prev
+}
+
+// Prompt returns the button label for action on the last-used device.
+func (in *InputState) Prompt(action InputAction) string {
+	return prompts[in.Device][action]
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestLastDevice(t *testing.T) {
+	tests := []struct {
+		name                       string
+		prev                       InputDevice
+		keyPressed, gamepadPressed bool
+		want                       InputDevice
+	}{
+		{"no presses keeps the keyboard", DeviceKeyboard, false, false, DeviceKeyboard},
+		{"no presses keeps the gamepad", DeviceGamepad, false, false, DeviceGamepad},
+		{"key press", DeviceGamepad, true, false, DeviceKeyboard},
+		{"button press", DeviceKeyboard, false, true, DeviceGamepad},
+		{"both at once favors the gamepad", DeviceKeyboard, true, true, DeviceGamepad},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastDevice(tt.prev, tt.keyPressed, tt.gamepadPressed); got != tt.want {
+				t.Errorf("lastDevice = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyMappingHas(t *testing.T) {
+	tests := []struct {
+		km   KeyMapping
+		key  ebiten.Key
+		want bool
+	}{
+		{playerOneKeys, ebiten.KeyLeft, true},
+		{playerOneKeys, ebiten.KeyX, true},
+		{playerOneKeys, ebiten.KeyShiftRight, true},
+		{playerOneKeys, ebiten.KeyA, false},
+		{playerTwoKeys, ebiten.KeyA, true},
+		{playerTwoKeys, ebiten.KeyC, true},
+		{playerTwoKeys, ebiten.KeySpace, false},
+	}
+	for _, tt := range tests {
+		if got := tt.km.has(tt.key); got != tt.want {
+			t.Errorf("%v in %+v = %v, want %v", tt.key, tt.km, got, tt.want)
+		}
+	}
+}
+
+func TestPromptFollowsDevice(t *testing.T) {
+	in := InputState{Device: DeviceKeyboard}
+	if got := in.Prompt(ActionAttack); got != "X" {
+		t.Errorf("keyboard attack prompt = %q, want %q", got, "X")
+	}
+	in.Device = lastDevice(in.Device, false, true)
+	if got := in.Prompt(ActionAttack); got != "(X)" {
+		t.Errorf("gamepad attack prompt = %q, want %q", got, "(X)")
+	}
+}
+
+func TestInputDelay(t *testing.T) {
+	script := []FrameInput{{Right: true}, {Jump: true, JumpPressed: true}, {Left: true}, {}, {AttackPressed: true}}
+	for _, frames := range []int{0, 1, 3} {
+		d := InputDelay{frames: frames}
+		for i, in := range script {
+			var want FrameInput
+			if i >= frames {
+				want = script[i-frames]
+			}
+			if got := d.Push(in); got != want {
+				t.Errorf("delay %d, tick %d: got %+v, want %+v", frames, i, got, want)
+			}
+		}
+		if len(d.queue) != frames {
+			t.Errorf("delay %d: %d inputs queued, want %d", frames, len(d.queue), frames)
+		}
+	}
+}
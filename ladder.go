@@ -0,0 +1,148 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ladderGIDs is the reverse of ladderTiles: the GID to use for each segment.
+var ladderGIDs = map[string]int{
+	"top":    62,
+	"middle": 82,
+	"bottom": 122,
+}
+
+// isLadderCell reports whether (tx, ty) holds one of the ladderTiles, the
+// same tiles checkLadder climbs.
+func isLadderCell(l *Layer, tx, ty int) bool {
+	return ladderSegment(l, tx, ty) != ""
+}
+
+// ladderSegment returns the ladderTiles type of the cell at (tx, ty): "top",
+// "middle", or "bottom", or "" for cells without a ladder tile.
+func ladderSegment(l *Layer, tx, ty int) string {
+	return ladderTiles[l.tileAt(tx, ty)]
+}
+
+// capSegment picks the segment a ladder cell should be from its neighbours:
+// "top" if no ladder is above it, "bottom" if none is below it, and
+// "middle" otherwise. A single-tile ladder is just a top cap so it can still
+// be climbed onto from below. It returns "" for cells without a ladder.
+func capSegment(l *Layer, tx, ty int) string {
+	if !isLadderCell(l, tx, ty) {
+		return ""
+	}
+	above := isLadderCell(l, tx, ty-1)
+	below := isLadderCell(l, tx, ty+1)
+	switch {
+	case !above:
+		return "top"
+	case !below:
+		return "bottom"
+	default:
+		return "middle"
+	}
+}
+
+// ladderRun returns the first and last columns of the horizontal run of
+// ladder cells in row ty that includes column tx, so a ladder several tiles
+// wide can be treated as one.
+func ladderRun(l *Layer, tx, ty int) (int, int) {
+	x0, x1 := tx, tx
+	for isLadderCell(l, x0-1, ty) {
+		x0--
+	}
+	for isLadderCell(l, x1+1, ty) {
+		x1++
+	}
+	return x0, x1
+}
+
+// ladderSnapSpeed is how many pixels per reference tick a climbing player
+// slides toward the middle of their ladder column.
+const ladderSnapSpeed = 0.5
+
+// snapToLadder eases the player toward the center of the ladder column
+// their center is in, the nearest one on a wide ladder, so they line up
+// with the rungs while climbing. k is the tick length in reference ticks.
+func (p *Player) snapToLadder(l *Layer, k float64) {
+	centerX := p.x + p.width/2
+	tx := int(math.Floor(centerX / float64(p.tileSize)))
+	ty := int(math.Floor((p.y + p.height/2) / float64(p.tileSize)))
+	if !isLadderCell(l, tx, ty) {
+		// Just entering at the bottom or leaving at the top: the ladder is
+		// only under the player's feet.
+		_, ty = tileSpan(p.y, p.height, float64(p.tileSize))
+	}
+	if !isLadderCell(l, tx, ty) {
+		return
+	}
+	target := float64(tx*p.tileSize) + float64(p.tileSize)/2 - p.width/2
+	step := ladderSnapSpeed * k
+	p.x += min(max(target-p.x, -step), step)
+}
+
+// ladderTop returns the y of the top edge of the ladder the player's feet
+// are in, if they're in its top cell.
+func (p *Player) ladderTop(l *Layer) (float64, bool) {
+	tx := int(math.Floor((p.x + p.width/2) / float64(p.tileSize)))
+	_, ty := tileSpan(p.y, p.height, float64(p.tileSize))
+	if ladderSegment(l, tx, ty) != "top" {
+		return 0, false
+	}
+	return float64(ty * p.tileSize), true
+}
+
+// ladderTopLanding reports whether moving down to newY lands the player on
+// the top of a ladder, and the y they land at. Tops hold the player like a
+// OneWay platform until they grab the ladder with Down.
+func (p *Player) ladderTopLanding(ladders *Layer, newY float64) (float64, bool) {
+	if ladders == nil || p.vy <= 0 || p.onLadder {
+		return 0, false
+	}
+	return p.platformLanding(newY, func(tx, ty int) bool { return ladderSegment(ladders, tx, ty) == "top" })
+}
+
+// placeLadder writes a vertical ladder of length tiles starting at (tx, ty)
+// into l, then re-caps the whole column so the new ladder (and any ladder it
+// joins) uses the proper top/middle/bottom GIDs that checkLadder expects.
+func placeLadder(l *Layer, tx, ty, length int) {
+	if tx < 0 || tx >= l.Width {
+		return
+	}
+	for y := ty; y < ty+length; y++ {
+		if y >= 0 && y < l.Height {
+			l.Data[y*l.Width+tx] = ladderGIDs["middle"]
+		}
+	}
+	for y := 0; y < l.Height; y++ {
+		if seg := capSegment(l, tx, y); seg != "" {
+			l.Data[y*l.Width+tx] = ladderGIDs[seg]
+		}
+	}
+}
+
+// drawLadderLayer renders every on-screen cell of the Ladders layer. Ladder
+// cells use the sprite for their ladderTiles segment, so caps and rungs
+// look right whatever flips the cell carries; any other tile is drawn as
+// it is. A hidden Ladders layer is still climbable but isn't drawn.
+func (g *Game) drawLadderLayer(screen *ebiten.Image, l *Layer) {
+	if l == nil || !l.Visible {
+		return
+	}
+	x0, y0, x1, y1 := g.camera.visibleTiles(l.Width, l.Height)
+	for ty := y0; ty < y1; ty++ {
+		for tx := x0; tx < x1; tx++ {
+			i := ty*l.Width + tx
+			if i >= len(l.Data) {
+				return
+			}
+			raw := l.Data[i]
+			if seg := ladderSegment(l, tx, ty); seg != "" {
+				raw = ladderGIDs[seg]
+			}
+			g.drawTile(screen, raw, tx, ty, g.camera.x, g.camera.y, l.Opacity)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import "testing"
+
+// testLadder builds a Ladders layer from rows of text: 'T', 'H', and 'B'
+// are the top, middle, and bottom ladder tiles and '.' is empty.
+func testLadder(rows ...string) *Layer {
+	gids := map[rune]int{'T': ladderGIDs["top"], 'H': ladderGIDs["middle"], 'B': ladderGIDs["bottom"]}
+	l := &Layer{Name: "Ladders", Type: "tilelayer", Width: len(rows[0]), Height: len(rows)}
+	for _, row := range rows {
+		for _, c := range row {
+			l.Data = append(l.Data, gids[c])
+		}
+	}
+	return l
+}
+
+func TestCheckLadder(t *testing.T) {
+	ladder := testLadder(
+		"....",
+		".T..",
+		".H..",
+		".B..",
+		"....",
+	)
+	short := testLadder(
+		"....",
+		".T..",
+		".H..",
+		".B..",
+		"....",
+	)
+	short.Data = short.Data[:12] // the bottom rung and below are missing
+
+	// The ladder column's center is x = 24, and a tile-wide player's
+	// center may be up to ladderCenterFraction of a tile (5px) from it.
+	tests := []struct {
+		name     string
+		ladder   *Layer
+		x, y     float64
+		onLadder bool
+		want     bool
+		wantType string
+	}{
+		{"entering at the bottom", ladder, 16, 40, false, true, "bottom"},
+		{"standing on the top", ladder, 16, 0, false, true, "top"},
+		{"climbing the middle", ladder, 16, 20, true, true, "middle"},
+		{"feet below the ladder while climbing", ladder, 16, 48, true, true, "bottom"},
+		{"feet below the ladder while not climbing", ladder, 16, 48, false, false, ""},
+		{"climbed off the top", ladder, 16, -1, true, false, ""},
+		{"center on the left edge of the threshold", ladder, 11, 20, false, true, "middle"},
+		{"center just past the left edge", ladder, 10.5, 20, false, false, ""},
+		{"center on the right edge of the threshold", ladder, 21, 20, false, true, "middle"},
+		{"center just past the right edge", ladder, 21.5, 20, false, false, ""},
+		{"off the map", ladder, -40, 20, true, false, ""},
+		{"short data skips the missing tiles", short, 16, 40, false, false, ""},
+		{"no layer", nil, 16, 40, false, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPlayer(tt.x, tt.y)
+			p.onLadder = tt.onLadder
+			got, gotType := p.checkLadder(tt.ladder)
+			if got != tt.want || gotType != tt.wantType {
+				t.Errorf("checkLadder at (%v, %v) = %v, %q, want %v, %q", tt.x, tt.y, got, gotType, tt.want, tt.wantType)
+			}
+		})
+	}
+}
+
+// TestClimbOffLadderTop climbs a three-tile ladder from the bottom and
+// checks the player ends up standing on it.
+func TestClimbOffLadderTop(t *testing.T) {
+	collision := testLayer(
+		"....",
+		"....",
+		"....",
+		"....",
+		"....",
+		"####",
+	)
+	ladders := testLadder(
+		"....",
+		"....",
+		".T..",
+		".H..",
+		".B..",
+		"....",
+	)
+	top := 32.0
+	p := testPlayer(16, 64)
+	p.onLadder = true
+	stepPlayer(&p, collision, ladders, FrameInput{Up: true}, 200, func(tick int) {
+		if p.y+p.height < top {
+			t.Fatalf("tick %d: climbed past the top of the ladder to y = %v", tick, p.y)
+		}
+	})
+	if p.y+p.height != top || !p.onGround || p.onLadder || p.vy != 0 {
+		t.Fatalf("after climbing: feet at %v, onGround %v, onLadder %v, vy %v; want standing on the top at %v",
+			p.y+p.height, p.onGround, p.onLadder, p.vy, top)
+	}
+	stepPlayer(&p, collision, ladders, FrameInput{}, 30, nil)
+	if p.y+p.height != top || !p.onGround {
+		t.Errorf("after letting go: feet at %v, onGround %v; want still standing on the top", p.y+p.height, p.onGround)
+	}
+}
+
+// TestClimbTwoWideLadder climbs a ladder two columns wide and checks the
+// player stays on it the whole way and lines up with the nearest column.
+func TestClimbTwoWideLadder(t *testing.T) {
+	collision := testLayer(
+		"....",
+		"....",
+		"....",
+		"....",
+		"....",
+		"####",
+	)
+	ladders := testLadder(
+		"....",
+		".TT.",
+		".HH.",
+		".HH.",
+		".BB.",
+		"....",
+	)
+	// Centered at x = 28, between the columns' centers at 24 and 40 but
+	// nearer the left one.
+	p := testPlayer(20, 48)
+	p.onLadder = true
+	climbing := false
+	stepPlayer(&p, collision, ladders, FrameInput{Up: true}, 200, func(tick int) {
+		if p.onLadder {
+			climbing = true
+		} else if climbing && !p.onGround {
+			t.Fatalf("tick %d: dropped off the ladder mid-climb at y = %v", tick, p.y)
+		}
+	})
+	if !climbing {
+		t.Fatal("never got on the ladder")
+	}
+	if p.x != 16 {
+		t.Errorf("x = %v after climbing, want 16, lined up with the nearest column", p.x)
+	}
+	if p.y+p.height != 16 || !p.onGround {
+		t.Errorf("feet at %v, onGround %v; want standing on the top at 16", p.y+p.height, p.onGround)
+	}
+}
+
+func TestLadderSegment(t *testing.T) {
+	// Segments come from the ladderTiles type of each cell, whatever its
+	// neighbours, and tiles that aren't in ladderTiles aren't ladders.
+	l := testLadder(
+		"BT..",
+		"HH..",
+		"TB..",
+	)
+	l.Data[2] = 1
+	l.Data[6] = ladderGIDs["middle"] | flipHorizontal
+	tests := []struct {
+		tx, ty int
+		want   string
+	}{
+		{0, 0, "bottom"},
+		{0, 1, "middle"},
+		{0, 2, "top"},
+		{1, 0, "top"},
+		{1, 2, "bottom"},
+		{2, 0, ""},
+		{2, 1, "middle"},
+		{3, 0, ""},
+		{0, -1, ""},
+	}
+	for _, tt := range tests {
+		if got := ladderSegment(l, tt.tx, tt.ty); got != tt.want {
+			t.Errorf("ladderSegment(%d, %d) = %q, want %q", tt.tx, tt.ty, got, tt.want)
+		}
+	}
+	if isLadderCell(l, 2, 0) {
+		t.Error("a tile outside ladderTiles counts as a ladder cell")
+	}
+}
+
+func TestCapSegment(t *testing.T) {
+	// Freshly placed ladders are all middles; the caps come from the
+	// neighbours.
+	l := testLadder(
+		"H.H.",
+		"H.H.",
+		"H...",
+		"....",
+	)
+	tests := []struct {
+		tx, ty int
+		want   string
+	}{
+		{0, 0, "top"},
+		{0, 1, "middle"},
+		{0, 2, "bottom"},
+		{2, 0, "top"},
+		{2, 1, "bottom"},
+		{1, 1, ""},
+		{0, -1, ""},
+		{0, 3, ""},
+	}
+	for _, tt := range tests {
+		if got := capSegment(l, tt.tx, tt.ty); got != tt.want {
+			t.Errorf("capSegment(%d, %d) = %q, want %q", tt.tx, tt.ty, got, tt.want)
+		}
+	}
+
+	single := testLadder(".H.")
+	if got := capSegment(single, 1, 0); got != "top" {
+		t.Errorf("single-tile ladder is %q, want a top cap", got)
+	}
+}
+
+func TestPlaceLadderRecaps(t *testing.T) {
+	l := testLadder(
+		".",
+		".",
+		".",
+		"T",
+		"B",
+	)
+	// A new two-tile ladder directly above joins the old one.
+	placeLadder(l, 0, 1, 2)
+	want := testLadder(
+		".",
+		"T",
+		"H",
+		"H",
+		"B",
+	)
+	for y := range l.Height {
+		if l.Data[y] != want.Data[y] {
+			t.Errorf("row %d = %d, want %d", y, l.Data[y], want.Data[y])
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// levelFS holds every level map. A level's name is its file name without
+// the .json extension, e.g. "tilemap".
+//
+//go:embed assets/*.json
+var levelFS embed.FS
+
+// readAsset returns the named file from g.assetDir if it's there, otherwise
+// from the assets directory of fsys.
+func (g *Game) readAsset(fsys embed.FS, name string) ([]byte, error) {
+	if g.assetDir != "" {
+		data, err := os.ReadFile(filepath.Join(g.assetDir, name))
+		if !errors.Is(err, fs.ErrNotExist) {
+			return data, err
+		}
+	}
+	return fsys.ReadFile("assets/" + name)
+}
+
+// readLevel decodes the named level map.
+func (g *Game) readLevel(name string) (TiledMap, error) {
+	var m TiledMap
+	data, err := g.readAsset(levelFS, name+".json")
+	if err != nil {
+		return m, fmt.Errorf("reading level %q: %w", name, err)
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("decoding level %q: %w", name, err)
+	}
+	return m, nil
+}
+
+// loadLevel makes the named level current and rebuilds everything spawned
+// from its map. The player's position and stats are left alone so callers
+// decide where they end up.
+func (g *Game) loadLevel(name string) error {
+	m, err := g.readLevel(name)
+	if err != nil {
+		return err
+	}
+	g.setLevel(name, m)
+	return nil
+}
+
+// setLevel installs an already-decoded map as the current level.
+func (g *Game) setLevel(name string, m TiledMap) {
+	g.tilemap = m
+	g.loadExternalTilesets()
+	g.tilemap.attachTiles(g.cfg.CollisionTilesets)
+	g.loadTilesetImages()
+	g.buildBackground()
+	if g.minimap.image != nil {
+		g.minimap.image.Deallocate()
+	}
+	g.minimap = buildMinimap(g.tilemap.collisionLayer())
+	g.level = name
+	g.powerUps = loadPowerUps(&g.tilemap)
+	g.coins = loadCoins(&g.tilemap)
+	g.blocks = loadBlocks(&g.tilemap)
+	g.checkpoints = loadCheckpoints(&g.tilemap)
+	g.triggers = loadTriggers(&g.tilemap)
+	g.messageTimer = 0
+	g.keys = loadKeys(&g.tilemap)
+	g.doors = loadDoors(&g.tilemap, float64(g.cfg.TileSize))
+	g.heldKeys = map[string]bool{}
+	g.shooters = loadShooters(&g.tilemap)
+	g.enemies = loadEnemies(&g.tilemap, float64(g.cfg.TileSize))
+	g.projectiles = ProjectilePool{}
+	g.particles = ParticlePool{}
+	g.setLighting(loadLighting(&g.tilemap, float64(g.cfg.TileSize), g.lightRadius))
+	g.camera.autoScroll = g.tilemap.FloatProperty("autoscroll", 0)
+	g.timeLeft = g.levelTime()
+	g.sound.PlayMusic(g.tilemap.StringProperty("music"))
+}
+
+// levelTime returns the current level's countdown in ticks, from its "time"
+// property in seconds. A "time" of 0 turns the countdown off.
+func (g *Game) levelTime() int {
+	return int(g.tilemap.FloatProperty("time", defaultLevelSeconds) * ebiten.DefaultTPS)
+}
+
+// startLevel loads the named level and puts the player at its PlayerStart
+// with no leftover motion. Health and effects carry over.
+func (g *Game) startLevel(name string) error {
+	if err := g.loadLevel(name); err != nil {
+		return err
+	}
+	g.spawnPlayer()
+	return nil
+}
+
+// spawnPlayer moves every player to the current level's PlayerStart
+// object, which also becomes the respawn point, stands them up, and clears
+// their velocity and ladder, jump, and ground state. Without a PlayerStart
+// they stay where they are.
+func (g *Game) spawnPlayer() {
+	start, ok := g.tilemap.objectNamed("PlayerStart")
+	for i := range g.players {
+		p := &g.players[i]
+		p.stopMotion()
+		if ok {
+			p.placeAt(start)
+		}
+	}
+	if ok {
+		g.respawnX, g.respawnY = g.players[0].x, g.players[0].y
+	}
+	mapW, mapH := g.mapSize()
+	g.camera.Snap(g.players, mapW, mapH)
+}
+
+// killY is the depth in pixels past which a falling player dies: the map's
+// "killy" property, or the bottom of the map.
+func (g *Game) killY() float64 {
+	_, mapH := g.mapSize()
+	return g.tilemap.FloatProperty("killy", mapH)
+}
+
+// stopMotion stands the player up and clears their velocity, their
+// ladder, rope, jump, and ground state, and the timers that steer them.
+func (p *Player) stopMotion() {
+	p.stand()
+	p.vx, p.vy = 0, 0
+	p.onGround, p.onLadder, p.onRope, p.isJumping = false, false, false, false
+	p.coyoteTimer, p.jumpBuffer, p.knockbackTimer, p.dashTimer = 0, 0, 0, 0
+}
+
+// respawn puts every dead player back at the last checkpoint (or the
+// start) with full health. The level itself carries on as it was:
+// collected coins and keys, open doors, and defeated enemies stay that
+// way, and living players aren't touched. A countdown that ran out starts
+// over.
+func (g *Game) respawn() {
+	for i := range g.players {
+		p := &g.players[i]
+		if p.health > 0 {
+			continue
+		}
+		p.stopMotion()
+		p.x, p.y = g.respawnX, g.respawnY
+		p.health = playerMaxHealth
+		p.invulnTimer = 0
+		p.effects = nil
+	}
+	if g.timeLeft == 0 {
+		g.timeLeft = g.levelTime()
+	}
+	mapW, mapH := g.mapSize()
+	g.camera.Snap(g.players, mapW, mapH)
+}
+
+// reloadLevel reloads the current level's map. It's a development aid for
+// iterating on a map while playing. With keepMomentum set, the players' whole
+// state — position, velocity, ground/ladder flags — survives the reload as
+// long as the new map is compatible: same dimensions, and no player is
+// left inside a solid tile. Otherwise the players stay put but stop moving.
+// It reports whether the players' momentum was kept.
+func (g *Game) reloadLevel(keepMomentum bool) (bool, error) {
+	oldW, oldH := g.tilemap.Width, g.tilemap.Height
+	if err := g.loadLevel(g.level); err != nil {
+		return false, err
+	}
+
+	compatible := g.tilemap.Width == oldW && g.tilemap.Height == oldH
+	if collision := g.tilemap.collisionLayer(); compatible && collision != nil {
+		for _, p := range g.players {
+			compatible = compatible && !p.collides(p.x, p.y, collision)
+		}
+	}
+	if keepMomentum && compatible {
+		return true, nil
+	}
+
+	for i := range g.players {
+		p := &g.players[i]
+		p.vx, p.vy = 0, 0
+		p.onLadder = false
+		p.onRope = false
+		p.onGround = false
+		p.ejectFromSolids(g.tilemap.collisionLayer())
+	}
+	return false, nil
+}
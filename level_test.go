@@ -0,0 +1,85 @@
+package main
+
+import "testing"
+
+// testLevel returns a game on an empty 20x10 map with a PlayerStart at the
+// left and a checkpoint further right, with one player spawned at the
+// start.
+func testLevel() *Game {
+	cfg := DefaultConfig()
+	g := &Game{cfg: cfg, camera: newCamera(cfg), lives: startingLives}
+	g.players = []Player{newPlayer(cfg)}
+	g.inputs = newInputs(cfg)
+	g.inputDelays = newInputDelays(len(g.inputs), cfg.InputDelay)
+	g.tilemap = TiledMap{Width: 20, Height: 10, Layers: []Layer{{
+		Name: "Objects",
+		Type: "objectgroup",
+		Objects: []Object{
+			{Name: "PlayerStart", X: 16, Y: 128, Width: 16, Height: 16},
+			{Name: "Flag", Type: "checkpoint", X: 160, Y: 128, Width: 16, Height: 16},
+		},
+	}}}
+	g.checkpoints = loadCheckpoints(&g.tilemap)
+	g.spawnPlayer()
+	return g
+}
+
+func TestRespawnAtCheckpoint(t *testing.T) {
+	g := testLevel()
+	p := &g.players[0]
+	if p.x != 16 || p.y != 128 {
+		t.Fatalf("spawned at (%v, %v), want the PlayerStart (16, 128)", p.x, p.y)
+	}
+
+	p.x = 160
+	g.checkCheckpoints()
+	if !g.checkpoints[0].active {
+		t.Fatal("checkpoint not activated by touching it")
+	}
+
+	p.x, p.y = 100, 40
+	p.health = 0
+	g.respawn()
+	if p.x != 160 || p.y != 128 {
+		t.Errorf("respawned at (%v, %v), want the checkpoint (160, 128)", p.x, p.y)
+	}
+	if p.health != playerMaxHealth {
+		t.Errorf("health after respawn = %d, want %d", p.health, playerMaxHealth)
+	}
+	if !g.checkpoints[0].active {
+		t.Error("checkpoint no longer active after respawn")
+	}
+}
+
+func TestRespawnOnlyDeadPlayers(t *testing.T) {
+	g := testLevel()
+	g.players = append(g.players, g.players[0])
+	g.coins = []Coin{{x: 64, y: 64, collected: true}}
+	alive, dead := &g.players[0], &g.players[1]
+	alive.x, alive.y = 80, 128
+	dead.x, dead.y = 120, 40
+	dead.health = 0
+
+	g.respawn()
+	if alive.x != 80 || alive.y != 128 {
+		t.Errorf("living player moved to (%v, %v) by another's respawn", alive.x, alive.y)
+	}
+	if dead.x != 16 || dead.y != 128 {
+		t.Errorf("dead player respawned at (%v, %v), want the start (16, 128)", dead.x, dead.y)
+	}
+	if !g.coins[0].collected {
+		t.Error("collected coin came back on respawn")
+	}
+}
+
+func TestSimultaneousDeathsCostOneLife(t *testing.T) {
+	g := testLevel()
+	g.players = append(g.players, g.players[0])
+	for i := range g.players {
+		g.players[i].y = g.killY() + 1
+	}
+	g.Step(nil)
+	if g.lives != startingLives-1 {
+		t.Errorf("lives = %d after two players died on one tick, want %d", g.lives, startingLives-1)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	darkness        = 0xeb // alpha of the dark where no light reaches
+	torchLightScale = 0.6  // a torch's radius as a fraction of the player's
+)
+
+// lighting is the state for levels with a true "dark" property: the
+// darkness drawn over the world, with holes around the player and every
+// tile with a true "light" property.
+type lighting struct {
+	on      bool
+	radius  float64       // the player's light radius in pixels
+	torches [][2]float64  // centers of the light tiles, in world pixels
+	mask    *ebiten.Image // the darkness, sized to the world image
+	glow    *ebiten.Image // a radial gradient, opaque in the middle
+}
+
+// loadLighting sets up lighting for m, whose tiles are ts pixels on a side.
+// The light around the player reaches radius pixels unless the map's
+// "lightradius" property says otherwise.
+func loadLighting(m *TiledMap, ts, radius float64) lighting {
+	lt := lighting{on: m.Properties.Bool("dark"), radius: m.FloatProperty("lightradius", radius)}
+	if !lt.on {
+		return lt
+	}
+	lights := m.tilesWithProperty("light")
+	for _, l := range m.Layers {
+		if l.Type != "tilelayer" {
+			continue
+		}
+		for i, raw := range l.Data {
+			if lights[tileGID(raw)] {
+				lt.torches = append(lt.torches, [2]float64{(float64(i%l.Width) + 0.5) * ts, (float64(i/l.Width) + 0.5) * ts})
+			}
+		}
+	}
+	return lt
+}
+
+// setLighting replaces the current lighting with lt, freeing the old
+// images.
+func (g *Game) setLighting(lt lighting) {
+	for _, img := range []*ebiten.Image{g.lighting.mask, g.lighting.glow} {
+		if img != nil {
+			img.Deallocate()
+		}
+	}
+	g.lighting = lt
+}
+
+// newGlow renders a radial gradient of radius r: fully opaque at the
+// center and smoothly fading to clear at the edge.
+func newGlow(r int) *ebiten.Image {
+	size := 2 * r
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := range size {
+		for x := range size {
+			d := math.Hypot(float64(x)+0.5-float64(r), float64(y)+0.5-float64(r)) / float64(r)
+			if d >= 1 {
+				continue
+			}
+			a := 1 - d*d*(3-2*d) // smoothstep falloff
+			img.SetRGBA(x, y, color.RGBA{A: uint8(a * 0xff)})
+		}
+	}
+	return ebiten.NewImageFromImage(img)
+}
+
+// drawLighting darkens the world image, leaving soft pools of light around
+// the players and the torches in view. It's drawn before the HUD, so the
+// HUD stays fully lit.
+func (g *Game) drawLighting(world *ebiten.Image) {
+	lt := &g.lighting
+	if !lt.on || lt.radius <= 0 {
+		return
+	}
+	r := int(math.Ceil(lt.radius))
+	if lt.glow == nil || lt.glow.Bounds().Dx() != 2*r {
+		if lt.glow != nil {
+			lt.glow.Deallocate()
+		}
+		lt.glow = newGlow(r)
+	}
+	b := world.Bounds()
+	if lt.mask == nil || lt.mask.Bounds() != b {
+		if lt.mask != nil {
+			lt.mask.Deallocate()
+		}
+		lt.mask = ebiten.NewImage(b.Dx(), b.Dy())
+	}
+	lt.mask.Fill(color.RGBA{A: darkness})
+
+	// Each light erases darkness where the gradient is opaque.
+	cut := func(x, y, scale float64) {
+		op := &ebiten.DrawImageOptions{Blend: ebiten.BlendDestinationOut}
+		op.GeoM.Translate(-float64(r), -float64(r))
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Translate(x-g.camera.x, y-g.camera.y)
+		lt.mask.DrawImage(lt.glow, op)
+	}
+	for _, p := range g.players {
+		cut(p.x+p.width/2, p.y+p.height/2, 1)
+	}
+	for _, t := range lt.torches {
+		cut(t[0], t[1], torchLightScale)
+	}
+	world.DrawImage(lt.mask, nil)
+}
@@ -3,12 +3,21 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"image"
+	"image/color"
 	_ "image/png"
 	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 
 	_ "embed"
 )
@@ -18,27 +27,13 @@ import (
 //go:embed assets/monochrome_tilemap_transparent_packed.png
 var tilesheetBytes []byte
 
+// defaultTilesheet is the file name of the embedded tilesheet, as maps
+// refer to it.
+const defaultTilesheet = "monochrome_tilemap_transparent_packed.png"
+
 //go:embed assets/tilemap.json
 var tilemapJSON []byte
 
-// TiledMap represents the JSON map exported from Tiled.
-type TiledMap struct {
-	Height     int     `json:"height"`
-	Width      int     `json:"width"`
-	Tilewidth  int     `json:"tilewidth"`
-	Tileheight int     `json:"tileheight"`
-	Layers     []Layer `json:"layers"`
-}
-
-// Layer represents a layer in the Tiled JSON.
-type Layer struct {
-	Name   string `json:"name"`
-	Data   []int  `json:"data"`
-	Width  int    `json:"width"`
-	Height int    `json:"height"`
-	Type   string `json:"type"`
-}
-
 var ladderTiles = map[int]string{
 	62:  "top",
 	82:  "middle",
@@ -46,26 +41,142 @@ var ladderTiles = map[int]string{
 }
 
 const (
-	screenWidth           = 160
-	screenHeight          = 160
-	tileSize              = 16  // tiles in the tilesheet are 16x16 pixels - 16bit SNES style!!
-	ladderCenterThreshold = 5.0 // +/- pixels for being considered in the center
-)
+	// ladderCenterFraction is how far, as a fraction of a tile, a
+	// tile-wide player's center can be from a ladder's center and still
+	// grab it: 5px with 16px tiles. See ladderCenterThreshold.
+	ladderCenterFraction = 5.0 / 16
+
+	attackDuration = 8  // ticks the melee hitbox stays out
+	attackCooldown = 24 // ticks from the start of one attack to the next
+	attackReach    = 12 // hitbox width in front of the player
+
+	dashTicks         = 8   // ticks a dash lasts
+	dashCooldownTicks = 45  // ticks from the start of one dash to the next
+	dashSpeedFactor   = 3.0 // dash speed as a multiple of walking speed
+
+	playerMaxHealth = 3
+	invulnTicks     = 60 // ticks of invulnerability after taking damage
+	startingLives   = 3
+
+	defaultLevelSeconds = 300 // countdown for levels without a "time" property
 
-var (
-	tilesImage   *ebiten.Image
-	tilemap      TiledMap
-	isFullscreen bool // Tracks fullscreen state
+	jumpCutFactor   = 0.4 // upward speed kept when jump is released early
+	coyoteTicks     = 6   // ticks after walking off a ledge that a jump still counts
+	jumpBufferTicks = 6   // ticks before landing that a jump press is remembered
+
+	oneWayDropThrough = true // holding Down drops through OneWay platforms
 )
 
+var debugMode bool // Enables developer tools such as no-clip
+
+// debugLog turns on the per-tick diagnostics printed through debugf. It's
+// set with the -debug flag.
+var debugLog bool
+
+// debugf logs a diagnostic message when debugLog is on.
+func debugf(format string, args ...any) {
+	if debugLog {
+		log.Printf(format, args...)
+	}
+}
+
 // Player holds the player's position, size, and velocity.
 type Player struct {
-	x, y          float64
-	vx, vy        float64
-	width, height float64
-	onGround      bool
-	onLadder      bool
-	isJumping     bool // Add this field to track jumping state
+	x, y           float64
+	vx, vy         float64
+	width, height  float64
+	onGround       bool
+	onLadder       bool
+	isJumping      bool // Add this field to track jumping state
+	health         int
+	facingLeft     bool // last horizontal direction the player moved in
+	attackTimer    int  // ticks left on the current melee attack
+	attackCooldown int  // ticks until another attack can start
+	noClip         bool // free-fly debug mode: no gravity or collision
+	noClipSpeed    float64
+	effects        []Effect // active timed power-up modifiers
+	physics        PhysicsConfig
+	surface        Surface // coefficients of the ground last landed on
+	anim           *Animation
+	animTick       int     // ticks since anim started
+	coyoteTimer    int     // ticks left to jump after walking off a ledge
+	jumpBuffer     int     // ticks left on a jump pressed while it couldn't be used
+	dropThrough    bool    // Down is held, so OneWay platforms don't catch the player
+	invulnTimer    int     // ticks left before the player can be hurt again
+	jumped, landed bool    // set on the tick the player jumps or lands, for sound
+	landSpeed      float64 // how fast the player was falling when they landed
+	hurt           bool    // set on the tick the player takes damage
+	knockbackTimer int     // ticks left with horizontal control taken away
+	dashTimer      int     // ticks left in the current dash
+	dashCooldown   int     // ticks until another dash can start
+	wallSide       int     // -1 or 1 while sliding down a wall on that side, else 0
+	crouching      bool
+	standHeight    float64 // height to return to when the crouch ends
+	onRope         bool
+	ropeSwing      float64 // horizontal speed built up swinging on a rope
+	ropeColumn     int     // tile column of the rope being held
+	ropeOffset     float64 // how far the player has swung from straight under the rope
+	brokeTile      bool    // set on the tick the player breaks a Breakable tile
+	teleportTimer  int     // ticks off every teleport pad before one fires again
+
+	// tileSize is the side of a map tile in pixels, from the game's Config.
+	// Positions are converted to tile coordinates with it.
+	tileSize int
+}
+
+// TakeDamage removes amount health from the player, stopping at zero, then
+// makes them briefly invulnerable so one hazard can't drain them at once.
+func (p *Player) TakeDamage(amount int) {
+	if p.hasEffect(PowerUpInvincible) || p.invulnTimer > 0 {
+		return
+	}
+	p.health -= amount
+	if p.health < 0 {
+		p.health = 0
+	}
+	p.invulnTimer = invulnTicks
+	p.hurt = true
+}
+
+// Resize changes the player's bounding box while keeping their feet and
+// horizontal center where they were. Movement in Update scales with the new
+// size automatically.
+func (p *Player) Resize(width, height float64) {
+	p.x += (p.width - width) / 2
+	p.y += p.height - height
+	p.width, p.height = width, height
+}
+
+// Kill drops the player's health to zero regardless of invincibility.
+func (p *Player) Kill() {
+	p.health = 0
+}
+
+// attackHitbox returns the melee hitbox in front of the player while an
+// attack is active. ok is false when the player isn't attacking.
+func (p *Player) attackHitbox() (x, y, w, h float64, ok bool) {
+	if p.attackTimer <= 0 {
+		return 0, 0, 0, 0, false
+	}
+	x = p.x + p.width
+	if p.facingLeft {
+		x = p.x - attackReach
+	}
+	return x, p.y + 2, attackReach, p.height - 4, true
+}
+
+// aabbOverlap reports whether two axis-aligned boxes intersect.
+func aabbOverlap(ax, ay, aw, ah, bx, by, bw, bh float64) bool {
+	return ax < bx+bw && ax+aw > bx && ay < by+bh && ay+ah > by
+}
+
+// ladderCenterThreshold returns how many pixels either side of a ladder's
+// center the player's center can be to grab it. It scales with the tile
+// size, and a player narrower than a tile gets the extra room on each side,
+// so the player's edges have the same leeway whatever the sizes.
+func (p *Player) ladderCenterThreshold() float64 {
+	ts := float64(p.tileSize)
+	return max(ladderCenterFraction*ts+(ts-p.width)/2, 1)
 }
 
 // checkLadder checks if the player is currently overlapping with a ladder tile.
@@ -73,35 +184,44 @@ type Player struct {
 // checkLadder checks if the player's horizontal center is within the center
 // of a ladder tile.
 func (p *Player) checkLadder(ladderLayer *Layer) (bool, string) {
+	if ladderLayer == nil {
+		return false, ""
+	}
 	playerCenterX := p.x + p.width/2
-	leftTile := int(p.x) / tileSize
-	rightTile := int(p.x+p.width) / tileSize
-	bottomTile := int(p.y+p.height) / tileSize
-	topTile := int(p.y) / tileSize
-
-	// Helper function to check if player's horizontal center is within a tile's center
-	isInLadderCenter := func(tileX int) bool {
-		tileCenterX := float64(tileX*tileSize) + float64(tileSize)/2
-		isCenter := playerCenterX >= tileCenterX-ladderCenterThreshold && playerCenterX <= tileCenterX+ladderCenterThreshold
-		log.Printf("checkCenter - playerCenterX: %.2f, tileX: %d, tileCenterX: %.2f, threshold: %.2f, isCenter: %v", playerCenterX, tileX, tileX, tileCenterX, ladderCenterThreshold, isCenter)
+	leftTile := int(p.x) / p.tileSize
+	rightTile := int(p.x+p.width) / p.tileSize
+	bottomTile := int(p.y+p.height) / p.tileSize
+	topTile := int(p.y) / p.tileSize
+
+	// Helper function to check if player's horizontal center is within the
+	// center of the ladder at a tile. A ladder several columns wide counts
+	// as one, from its leftmost column's center to its rightmost's, so the
+	// player can cross it without falling off between columns.
+	ladderCenterThreshold := p.ladderCenterThreshold()
+	isInLadderCenter := func(tileX, tileY int) bool {
+		x0, x1 := ladderRun(ladderLayer, tileX, tileY)
+		lo := float64(x0*p.tileSize) + float64(p.tileSize)/2 - ladderCenterThreshold
+		hi := float64(x1*p.tileSize) + float64(p.tileSize)/2 + ladderCenterThreshold
+		isCenter := playerCenterX >= lo && playerCenterX <= hi
+		debugf("checkCenter - playerCenterX: %.2f, tileX: %d, span: %.2f-%.2f, isCenter: %v", playerCenterX, tileX, lo, hi, isCenter)
 		return isCenter
 	}
 
 	// Check bottom edge for entering
 	for tx := leftTile; tx <= rightTile; tx++ {
 		for ty := bottomTile; ty <= bottomTile; ty++ {
-			log.Printf("checkLadder (entry) - tx: %d, ty: %d, playerY: %.2f, bottomTileY: %d", tx, ty, p.y, ty*tileSize)
+			debugf("checkLadder (entry) - tx: %d, ty: %d, playerY: %.2f, bottomTileY: %d", tx, ty, p.y, ty*p.tileSize)
 			if tx < 0 || ty < 0 || tx >= ladderLayer.Width || ty >= ladderLayer.Height {
 				continue
 			}
 			tileIndex := ty*ladderLayer.Width + tx
 			if tileIndex < 0 || tileIndex >= len(ladderLayer.Data) { // check for valid tileIndex
-				log.Printf("checkLadder (entry) - tileIndex out of bounds: %d, len(ladderLayer.Data): %d", tileIndex, len(ladderLayer.Data))
+				debugf("checkLadder (entry) - tileIndex out of bounds: %d, len(ladderLayer.Data): %d", tileIndex, len(ladderLayer.Data))
 				continue
 			}
-			tile := ladderLayer.Data[tileIndex]
-			if ladderType, ok := ladderTiles[tile]; ok && isInLadderCenter(tx) {
-				log.Printf("checkLadder (entry) - Found ladder tile: %d (%s) at (%d, %d)", tile, ladderType, tx, ty)
+			tile := tileGID(ladderLayer.Data[tileIndex])
+			if ladderType, ok := ladderTiles[tile]; ok && isInLadderCenter(tx, ty) {
+				debugf("checkLadder (entry) - Found ladder tile: %d (%s) at (%d, %d)", tile, ladderType, tx, ty)
 				return true, ladderType
 			}
 		}
@@ -111,18 +231,18 @@ func (p *Player) checkLadder(ladderLayer *Layer) (bool, string) {
 	if p.onLadder {
 		for ty := topTile; ty <= bottomTile; ty++ {
 			for tx := leftTile; tx <= rightTile; tx++ {
-				log.Printf("checkLadder (onLadder) - tx: %d, ty: %d, playerY: %.2f, tileY: %d", tx, ty, p.y, ty*tileSize)
+				debugf("checkLadder (onLadder) - tx: %d, ty: %d, playerY: %.2f, tileY: %d", tx, ty, p.y, ty*p.tileSize)
 				if tx < 0 || ty < 0 || tx >= ladderLayer.Width || ty >= ladderLayer.Height {
 					continue
 				}
 				tileIndex := ty*ladderLayer.Width + tx
 				if tileIndex < 0 || tileIndex >= len(ladderLayer.Data) { // check for valid tileIndex
-					log.Printf("checkLadder (onLadder) - tileIndex out of bounds: %d, len(ladderLayer.Data): %d", tileIndex, len(ladderLayer.Data))
+					debugf("checkLadder (onLadder) - tileIndex out of bounds: %d, len(ladderLayer.Data): %d", tileIndex, len(ladderLayer.Data))
 					continue
 				}
-				tile := ladderLayer.Data[tileIndex]
-				if ladderType, ok := ladderTiles[tile]; ok && isInLadderCenter(tx) {
-					log.Printf("checkLadder (onLadder) - Found ladder tile: %d (%s) at (%d, %d)", tile, ladderType, tx, ty)
+				tile := tileGID(ladderLayer.Data[tileIndex])
+				if ladderType, ok := ladderTiles[tile]; ok && isInLadderCenter(tx, ty) {
+					debugf("checkLadder (onLadder) - Found ladder tile: %d (%s) at (%d, %d)", tile, ladderType, tx, ty)
 					return true, ladderType
 				}
 			}
@@ -132,17 +252,31 @@ func (p *Player) checkLadder(ladderLayer *Layer) (bool, string) {
 	return false, ""
 }
 
+// tileSpan returns the first and last tile, of tiles ts pixels on a side,
+// that the span [pos, pos+size) covers on one axis.
+//
+// Positions stay fractional; only the tile lookup rounds. The start is
+// floored to the tile containing it, and the end is exclusive, so a span
+// ending exactly on a tile boundary doesn't touch the next tile. Flooring
+// (rather than truncating toward zero) keeps this the same for negative
+// positions, so slow movement samples the same tiles every tick and doesn't
+// jitter against walls. Every box-against-tiles check uses it so they all
+// agree on which tiles a box is in.
+func tileSpan(pos, size, ts float64) (first, last int) {
+	return int(math.Floor(pos / ts)), int(math.Ceil((pos+size)/ts)) - 1
+}
+
 // collides checks whether the player's bounding box at (newX, newY)
-// would intersect any solid tile in the collision layer.
+// would intersect any solid tile in the collision layer. The box covers
+// the tiles tileSpan gives on each axis.
 func (p *Player) collides(newX, newY float64, collision *Layer) bool {
 	if collision == nil {
 		return false
 	}
 	// Determine the tiles covered by the player's new bounding box.
-	leftTile := int(newX) / tileSize
-	rightTile := int(newX+p.width) / tileSize
-	topTile := int(newY) / tileSize
-	bottomTile := int(newY+p.height) / tileSize
+	ts := float64(p.tileSize)
+	leftTile, rightTile := tileSpan(newX, p.width, ts)
+	topTile, bottomTile := tileSpan(newY, p.height, ts)
 
 	for ty := topTile; ty <= bottomTile; ty++ {
 		for tx := leftTile; tx <= rightTile; tx++ {
@@ -150,19 +284,21 @@ func (p *Player) collides(newX, newY float64, collision *Layer) bool {
 			if tx < 0 || ty < 0 || tx >= collision.Width || ty >= collision.Height {
 				continue
 			}
+			if collision.tiles.isBlocked(tx, ty) {
+				return true
+			}
 			tileIndex := ty*collision.Width + tx
-			if tileIndex < 0 || tileIndex >= len(collision.Data) { // Check for valid index
-				log.Printf("collides - tileIndex out of bounds: %d, len(collision.Data): %d", tileIndex, len(collision.Data))
-				return false // IMPORTANT:  Return false to prevent a crash.  No collision if index is bad.
+			if tileIndex < 0 || tileIndex >= len(collision.Data) {
+				// Data is shorter than Width*Height. Skip just this tile so
+				// the rest of the box is still checked.
+				debugf("collides - tileIndex out of bounds: %d, len(collision.Data): %d", tileIndex, len(collision.Data))
+				continue
 			}
-			tile := collision.Data[tileIndex]
+			tile := tileGID(collision.Data[tileIndex])
 			if tile != 0 {
-				// Colliding with a solid tile.
-				if p.vy <= 0 && newY < float64(ty*tileSize+tileSize) && newY+p.height > float64(ty*tileSize) {
-					// Allow entering platform from below
-					if p.vy < 0 {
-						return false
-					}
+				// One-way platforms let the player jump up through them.
+				if collision.tiles.isOneWay(tile) && p.vy < 0 {
+					continue
 				}
 				return true
 			}
@@ -172,29 +308,157 @@ func (p *Player) collides(newX, newY float64, collision *Layer) bool {
 }
 
 // Move updates the player's position while checking for collisions.
-// It applies horizontal and vertical movement separately.
-func (p *Player) Move(collision *Layer) {
-	// Try horizontal movement.
-	newX := p.x + p.vx
-	if collision != nil && p.collides(newX, p.y, collision) {
-		// Horizontal collision: cancel horizontal velocity.
+// It applies horizontal and vertical movement separately, in the order set
+// by p.physics.ResolveOrder. k is the length of the tick in reference ticks
+// (see tickScale). A long tick is split into substeps that each move less
+// than half a tile, so a large scaled velocity can't skip over a tile.
+// Tiles of the breakable layer are solid until the player breaks them, and
+// blocks are solid but can be pushed.
+func (p *Player) Move(collision, oneWay, ladders, breakable *Layer, blocks []Block, k float64) {
+	maxStep := float64(p.tileSize) / 2
+	dist := math.Max(math.Abs(p.vx), math.Abs(p.vy)) * k
+	steps := max(1, int(math.Ceil(dist/maxStep)))
+	sk := k / float64(steps)
+
+	for range steps {
+		xFirst := true
+		switch p.physics.ResolveOrder {
+		case ResolveYThenX:
+			xFirst = false
+		case ResolveLargerFirst:
+			xFirst = math.Abs(p.vx) >= math.Abs(p.vy)
+		}
+		if xFirst {
+			p.moveX(collision, breakable, blocks, sk)
+			p.moveY(collision, oneWay, ladders, breakable, blocks, sk)
+		} else {
+			p.moveY(collision, oneWay, ladders, breakable, blocks, sk)
+			p.moveX(collision, breakable, blocks, sk)
+		}
+	}
+}
+
+// sweep finds how far along one axis the player can go from a free position
+// toward a blocked one before hitting something, by bisecting between them.
+// The result is always a free position, within a fraction of a pixel of the
+// obstacle. Tiles and the player are whole pixels, so when the whole pixel
+// in that last fraction is free it's used instead: the player then sits
+// exactly against the obstacle rather than just short of it. Move keeps each
+// step under half a tile, so the first solid tile on the path can't be
+// skipped.
+func sweep(from, to float64, free func(float64) bool) float64 {
+	const iterations = 8
+	lo, hi := from, to
+	for range iterations {
+		mid := (lo + hi) / 2
+		if free(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	if r := math.Round(lo); r >= min(lo, hi) && r <= max(lo, hi) && free(r) {
+		return r
+	}
+	return lo
+}
+
+// moveX applies horizontal movement over k reference ticks. Walking into
+// a block on the ground pushes it along at reduced speed if it has room.
+func (p *Player) moveX(collision, breakable *Layer, blocks []Block, k float64) {
+	newX := p.x + p.vx*k
+	if p.onGround {
+		newX += p.surface.Impulse * k
+	}
+	ts := float64(p.tileSize)
+	if i := blockAt(blocks, ts, newX, p.y, p.width, p.height, -1); i >= 0 && p.onGround {
+		newX = p.x + (newX-p.x)*blockPushFactor
+		dx := newX + p.width - blocks[i].x
+		if newX < p.x {
+			dx = newX - (blocks[i].x + ts)
+		}
+		pushBlock(blocks, i, ts, dx, collision)
+	}
+	blocked := func(x float64) bool {
+		return p.collides(x, p.y, collision) || p.collides(x, p.y, breakable) ||
+			blockAt(blocks, ts, x, p.y, p.width, p.height, -1) >= 0
+	}
+	if blocked(newX) {
+		// Horizontal collision: slide up against the wall, then cancel
+		// horizontal velocity.
+		p.x = sweep(p.x, newX, func(x float64) bool { return !blocked(x) })
 		p.vx = 0
+		p.dashTimer = 0
 	} else {
-		// Clamp horizontal position to stay within screen bounds
+		// Clamp horizontal position to stay within the map, which is as
+		// wide as its collision layer. Without one only the left edge
+		// stops the player.
 		p.x = newX
 		if p.x < 0 {
 			p.x = 0
 		}
-		if p.x+p.width > screenWidth {
-			p.x = screenWidth - p.width
+		if collision != nil {
+			if maxX := float64(collision.Width * p.tileSize); p.x+p.width > maxX {
+				p.x = maxX - p.width
+			}
 		}
 	}
-	// Try vertical movement.
-	newY := p.y + p.vy
-	if collision != nil && p.collides(p.x, newY, collision) {
-		// Vertical collision: cancel vertical velocity.
-		// If moving downward, we assume the player hit the ground.
+}
+
+// moveY applies vertical movement over k reference ticks. Falling fast
+// enough onto breakable tiles smashes through them, and jumping into them
+// from below breaks them and stops the jump.
+func (p *Player) moveY(collision, oneWay, ladders, breakable *Layer, blocks []Block, k float64) {
+	newY := p.y + p.vy*k
+	if p.vy >= breakStompSpeed && p.collides(p.x, newY, breakable) {
+		p.brokeTile = p.breakTiles(breakable, p.x, newY) || p.brokeTile
+	}
+	blocked := func(y float64) bool {
+		return p.collides(p.x, y, collision) || p.collides(p.x, y, breakable) ||
+			blockAt(blocks, float64(p.tileSize), p.x, y, p.width, p.height, -1) >= 0
+	}
+	top, ok := p.oneWayLanding(oneWay, newY)
+	if !ok {
+		top, ok = p.ladderTopLanding(ladders, newY)
+	}
+	if ok && !(collision != nil && p.collides(p.x, top-p.height, collision)) {
+		p.y = top - p.height
+		p.vy = 0
+		p.onGround = true
+		p.isJumping = false
+		p.surface = defaultSurface
+		return
+	}
+	if blocked(newY) {
+		// Vertical collision: move up to the surface, then cancel vertical
+		// velocity. If moving downward, we assume the player hit the ground.
+		p.y = sweep(p.y, newY, func(y float64) bool { return !blocked(y) })
+		if p.vy < 0 && p.collides(p.x, newY, breakable) {
+			p.brokeTile = p.breakTiles(breakable, p.x, newY) || p.brokeTile
+		}
 		if p.vy > 0 {
+			// Blocks and breakable tiles can stop a fall on a map with no
+			// Collision layer; they're ordinary ground.
+			p.surface = defaultSurface
+			if collision != nil {
+				p.surface = collision.tiles.surface(p.groundTile(collision, newY+p.height))
+			}
+			if p.surface.Spring {
+				p.vy = springSpeed
+				p.onGround = false
+				p.isJumping = false
+				return
+			}
+			if launch := p.vy * p.surface.Bounce; launch >= minBounceSpeed {
+				// Bouncy ground sends part of the landing speed back up.
+				p.vy = -launch
+				p.onGround = false
+				return
+			}
+			if !p.onGround {
+				p.landed = true
+				p.landSpeed = p.vy
+			}
 			p.onGround = true
 			p.isJumping = false // Reset jumping state when landing
 		}
@@ -207,118 +471,338 @@ func (p *Player) Move(collision *Layer) {
 	}
 }
 
-// Update handles input and physics for the player.
-func (p *Player) Update(collision *Layer, ladderLayer *Layer) {
-	// Constants for movement.
-	const speed = 1.5
-	const jumpSpeed = -5.0
-	const gravity = 0.3
+// oneWayLanding reports whether moving down to newY lands the player on a
+// tile of the OneWay layer, and the y of that tile's top. A tile only counts
+// if the player's feet start at or above its top, so they can jump up through
+// it from below or walk out of it sideways. Holding Down (dropThrough) falls
+// through instead.
+func (p *Player) oneWayLanding(oneWay *Layer, newY float64) (float64, bool) {
+	if oneWay == nil || p.vy <= 0 || p.dropThrough {
+		return 0, false
+	}
+	return p.platformLanding(newY, func(tx, ty int) bool { return oneWay.tileAt(tx, ty) != 0 })
+}
+
+// platformLanding does the work of oneWayLanding for any kind of tile that
+// can be stood on from above, as reported by platform.
+func (p *Player) platformLanding(newY float64, platform func(tx, ty int) bool) (float64, bool) {
+	feet, newFeet := p.y+p.height, newY+p.height
+	left, right := tileSpan(p.x, p.width, float64(p.tileSize))
+	for ty := int(math.Floor(feet / float64(p.tileSize))); float64(ty*p.tileSize) < newFeet; ty++ {
+		top := float64(ty * p.tileSize)
+		if top < feet {
+			continue
+		}
+		for tx := left; tx <= right; tx++ {
+			if platform(tx, ty) {
+				return top, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// referenceTPS is the tick rate the movement constants were tuned at.
+// Velocities are in pixels per reference tick.
+const referenceTPS = 60
+
+// tickScale converts a tick length in seconds into reference ticks.
+func tickScale(dt float64) float64 {
+	return dt * referenceTPS
+}
+
+// Update handles input and physics for the player. dt is the length of the
+// tick in seconds; velocities and gravity are scaled by it so movement is
+// the same at any tick rate.
+func (p *Player) Update(collision, ladderLayer, oneWayLayer, water, rope, breakable *Layer, blocks []Block, in FrameInput, dt float64) {
+	k := tickScale(dt)
+
+	// Movement settings, tuned for a one-tile-tall player.
+	baseSpeed := p.physics.Speed
+	baseJumpSpeed := p.physics.JumpSpeed
+	gravity := p.physics.Gravity
+
+	p.updateCrouch(collision, in)
+
+	// Scale movement with the player's size. Jump height is v²/2g, so scaling
+	// the jump speed by sqrt(height) makes jump height grow in proportion to
+	// the player; walking speed gets the same factor so a big player doesn't
+	// feel sluggish relative to their jumps.
+	sizeScale := math.Sqrt(p.standingHeight() / float64(p.tileSize))
+	speed := baseSpeed * sizeScale * p.speedMultiplier()
+	jumpSpeed := baseJumpSpeed * sizeScale * p.jumpMultiplier()
+	if p.crouching {
+		speed *= crouchSpeedFactor
+	}
+
+	// Underwater the player is slower, floatier, and swims instead of
+	// jumping.
+	fallCap := p.physics.MaxFall
+	swimming := !p.onLadder && p.inWater(water)
+	if swimming {
+		speed *= waterSpeedFactor
+		gravity *= waterGravityFactor
+		fallCap = waterMaxFallSpeed
+	}
+
+	p.updateEffects()
+	p.jumped, p.landed, p.hurt, p.brokeTile = false, false, false, false
+	if p.invulnTimer > 0 {
+		p.invulnTimer--
+	}
+
+	if p.noClip {
+		p.updateNoClip(in, k)
+		return
+	}
+
+	if p.updateRope(rope, collision, breakable, blocks, in, speed, jumpSpeed, k) {
+		p.updateAnimation()
+		return
+	}
 
 	isOnLadder, ladderType := p.checkLadder(ladderLayer)
 
-	log.Printf("Update - Start: onLadder: %v, isOnLadder: %v, ladderType: %s, p.x: %.2f, p.y: %.2f, p.vx: %.2f, p.vy: %.2f",
+	debugf("Update - Start: onLadder: %v, isOnLadder: %v, ladderType: %s, p.x: %.2f, p.y: %.2f, p.vx: %.2f, p.vy: %.2f",
 		p.onLadder, isOnLadder, ladderType, p.x, p.y, p.vx, p.vy)
 
-	// Handle transition off ladder when at the top
-	if p.onLadder && ladderType == "top" && p.vy < 0 {
-		// Check if player's top edge is above the top of the tile
-		playerTopY := p.y
-		tileTopY := float64(int(p.y+p.height)/tileSize) * tileSize
-		if playerTopY <= tileTopY {
+	// Climbing off the top: once the player's feet are within one climbing
+	// step of the ladder's top edge, stand them on it. The top then holds
+	// them like a platform (see ladderTopLanding).
+	if p.onLadder && p.vy < 0 && ladderLayer != nil {
+		if top, ok := p.ladderTop(ladderLayer); ok && p.y+p.height-top <= -p.vy*k &&
+			!(collision != nil && p.collides(p.x, top-p.height, collision)) {
+			p.y = top - p.height
+			p.vy = 0
 			p.onLadder = false
-			p.onGround = false
-			log.Println("Update - Exiting ladder at the top")
+			p.onGround = true
+			p.isJumping = false
+			debugf("Update - Exiting ladder at the top")
 		}
 	}
 
-	// Transitioning onto a ladder
-	if !p.onLadder && isOnLadder {
+	// Transitioning onto a ladder. Someone standing on a ladder's top only
+	// grabs it by pressing Down.
+	standingOnTop := p.onGround && ladderType == "top" && !in.Down
+	if !p.onLadder && isOnLadder && !standingOnTop {
 		if p.vy >= 0 {
 			p.onLadder = true
 			p.vy = 0
-			log.Println("Update - Transitioned onto ladder (downward/standing)")
-		} else if ebiten.IsKeyPressed(ebiten.KeyUp) {
+			debugf("Update - Transitioned onto ladder (downward/standing)")
+		} else if in.Up {
 			p.onLadder = true
 			p.vy = -speed
 			p.onGround = false
-			log.Println("Update - Transitioned onto ladder (pressing up)")
+			debugf("Update - Transitioned onto ladder (pressing up)")
 		}
 	}
 
+	if in.JumpPressed {
+		p.jumpBuffer = jumpBufferTicks
+	}
+
 	// Jumping off the ladder
-	if p.onLadder && inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+	if p.onLadder && in.JumpPressed {
 		p.onLadder = false
 		p.vy = jumpSpeed
 		p.onGround = false
 		p.isJumping = true
-		log.Println("Update - Jumped off ladder")
+		p.jumped = true
+		p.jumpBuffer = 0
+		debugf("Update - Jumped off ladder")
+	}
+
+	// Melee attack. The hitbox follows the player, so attacking works while
+	// walking or mid-jump.
+	if p.attackTimer > 0 {
+		p.attackTimer--
+	}
+	if p.attackCooldown > 0 {
+		p.attackCooldown--
+	}
+	if in.AttackPressed && p.attackCooldown == 0 && !p.onLadder {
+		p.attackTimer = attackDuration
+		p.attackCooldown = attackCooldown
+		debugf("Update - Attack")
 	}
 
-	// Handle horizontal movement
-	if ebiten.IsKeyPressed(ebiten.KeyLeft) {
+	// Dashing: a press while moving sideways gives a short burst of speed
+	// in that direction. Gravity still applies, so dashing off a ledge
+	// falls as usual.
+	if p.dashCooldown > 0 {
+		p.dashCooldown--
+	}
+	if in.DashPressed && p.dashCooldown == 0 && !p.onLadder && p.knockbackTimer == 0 && (in.Left || in.Right) {
+		p.dashTimer = dashTicks
+		p.dashCooldown = dashCooldownTicks
+		p.facingLeft = in.Left
+		debugf("Update - Dash")
+	}
+
+	// Handle horizontal movement. While knocked back the player can't steer
+	// and friction doesn't apply, so the knockback carries them.
+	if p.knockbackTimer > 0 {
+		p.knockbackTimer--
+	} else if p.dashTimer > 0 {
+		p.dashTimer--
+		p.vx = speed * dashSpeedFactor
+		if p.facingLeft {
+			p.vx = -p.vx
+		}
+	} else if in.Left {
 		p.vx = -speed
+		p.facingLeft = true
 		// If on ladder and moving horizontally, transition off
 		if p.onLadder {
 			p.onLadder = false
 			p.onGround = false
-			log.Println("Update - left off ladder")
+			debugf("Update - left off ladder")
 		}
-	} else if ebiten.IsKeyPressed(ebiten.KeyRight) {
+	} else if in.Right {
 		p.vx = speed
+		p.facingLeft = false
 		// If on ladder and moving horizontally, transition off
 		if p.onLadder {
 			p.onLadder = false
 			p.onGround = false
-			log.Println("Update - right off ladder")
+			debugf("Update - right off ladder")
 		}
 	} else {
-		p.vx = 0
+		// Without input the ground's friction slows the player; in the air
+		// they stop immediately as before.
+		friction := 1.0
+		if p.onGround {
+			friction = p.surface.Friction
+		}
+		p.vx *= math.Pow(1-friction, k)
+		if math.Abs(p.vx) < 0.05 {
+			p.vx = 0
+		}
 	}
 
 	// Vertical Ladder movement
 	if p.onLadder {
-		if ebiten.IsKeyPressed(ebiten.KeyUp) {
+		if (in.Up || in.Down) && ladderLayer != nil {
+			p.snapToLadder(ladderLayer, k)
+		}
+		if in.Up {
 			p.vy = -speed
 			p.onGround = false
-			log.Println("Update - Climbing ladder up")
-		} else if ebiten.IsKeyPressed(ebiten.KeyDown) {
+			debugf("Update - Climbing ladder up")
+		} else if in.Down {
 			p.vy = speed
 			p.onGround = false
-			log.Println("Update - Climbing ladder down")
+			debugf("Update - Climbing ladder down")
 		} else {
 			p.vy = 0
-			log.Println("Update - On ladder, no vertical input")
+			debugf("Update - On ladder, no vertical input")
 		}
 	} else {
-		// Apply gravity if not on ladder
-		p.vy += gravity
-		log.Printf("Update - Applying gravity, p.vy: %.2f", p.vy)
+		// Apply gravity if not on ladder. Half is applied before moving and
+		// half after, which integrates constant acceleration exactly, so jump
+		// height doesn't depend on the tick length.
+		p.vy = min(p.vy+gravity*k/2, fallCap)
+		debugf("Update - Applying gravity, p.vy: %.2f", p.vy)
 	}
 
-	// Regular jump
-	if p.onGround && !p.onLadder && inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+	// Swimming: holding Up strokes toward the surface, and jumping with
+	// the player's head above water hops them out.
+	if swimming {
+		if in.Up {
+			p.vy = max(p.vy-swimStroke*k, -swimSpeed)
+			p.onGround = false
+		}
+		if p.jumpBuffer > 0 && p.atWaterSurface(water) {
+			p.vy = jumpSpeed * waterJumpFactor
+			p.onGround = false
+			p.isJumping = true
+			p.jumpBuffer = 0
+			p.jumped = true
+			debugf("Update - Jumped out of water")
+		}
+	}
+
+	// Pushing into a wall while falling slides down it slowly, and jumping
+	// from the slide kicks the player up and away from the wall. Steering
+	// is locked briefly so holding toward the wall doesn't cancel the kick.
+	p.wallSide = 0
+	if !p.onGround && !p.onLadder && !swimming && collision != nil && p.vy > 0 {
+		if side := p.wallContact(collision); (side < 0 && in.Left) || (side > 0 && in.Right) {
+			p.wallSide = side
+			p.vy = min(p.vy, wallSlideSpeed)
+		}
+	}
+	if p.wallSide != 0 && p.jumpBuffer > 0 {
 		p.vy = jumpSpeed
+		p.vx = -float64(p.wallSide) * speed * wallJumpPush
+		p.facingLeft = p.wallSide > 0
+		p.knockbackTimer = wallJumpTicks
+		p.isJumping = true
+		p.jumpBuffer = 0
+		p.jumped = true
+		p.wallSide = 0
+		debugf("Update - Wall jump")
+	}
+
+	// Regular jump, also allowed for a few ticks after walking off a ledge.
+	// A press shortly before landing is buffered and fires on touchdown.
+	if (p.onGround || p.coyoteTimer > 0) && !p.onLadder && !swimming && p.jumpBuffer > 0 {
+		p.vy = jumpSpeed
+		if !in.Jump {
+			// Jump was already let go, so this is a tap.
+			p.vy *= jumpCutFactor
+		}
 		p.onGround = false
 		p.isJumping = true
-		log.Println("Update - Regular jump")
+		p.coyoteTimer = 0
+		p.jumpBuffer = 0
+		p.jumped = true
+		debugf("Update - Regular jump")
+	}
+	if p.coyoteTimer > 0 {
+		p.coyoteTimer--
+	}
+	if p.jumpBuffer > 0 {
+		p.jumpBuffer--
+	}
+
+	// Letting go of jump on the way up cuts the jump short, so a tap gives a
+	// short hop and holding gives the full height.
+	if p.isJumping && in.JumpReleased && p.vy < 0 {
+		p.vy *= jumpCutFactor
+		debugf("Update - Jump cut short")
 	}
 
 	// Move the player
-	p.Move(collision)
+	wasOnGround := p.onGround
+	p.dropThrough = oneWayDropThrough && in.Down && !p.onLadder
+	p.Move(collision, oneWayLayer, ladderLayer, breakable, blocks, k)
+	if !p.onLadder && p.vy != 0 {
+		p.vy = min(p.vy+gravity*k/2, fallCap)
+	}
+	if p.wallSide != 0 {
+		p.vy = min(p.vy, wallSlideSpeed)
+	}
+	switch {
+	case p.onGround:
+		p.coyoteTimer = 0
+	case wasOnGround && !p.isJumping:
+		p.coyoteTimer = coyoteTicks
+	}
 
 	// Leaving a ladder
 	if p.onLadder && !isOnLadder {
 		p.onLadder = false
-		log.Println("Update - Left ladder (not overlapping anymore)")
+		debugf("Update - Left ladder (not overlapping anymore)")
 	}
 
 	// Prevent going below ground on ladder
 	if p.onLadder && ladderLayer != nil && len(ladderLayer.Data) > 0 {
-		bottomLadderY := float64(ladderLayer.Height*tileSize) - tileSize
-		if p.y+p.height > bottomLadderY+tileSize {
-			p.y = bottomLadderY + tileSize - p.height
+		bottomLadderY := float64(ladderLayer.Height*p.tileSize - p.tileSize)
+		if p.y+p.height > bottomLadderY+float64(p.tileSize) {
+			p.y = bottomLadderY + float64(p.tileSize) - p.height
 			p.vy = 0
-			log.Printf("Update - Prevented going below bottom of ladder at Y: %.2f", bottomLadderY)
+			debugf("Update - Prevented going below bottom of ladder at Y: %.2f", bottomLadderY)
 		}
 	}
 
@@ -327,142 +811,724 @@ func (p *Player) Update(collision *Layer, ladderLayer *Layer) {
 		p.y = 0
 		if p.vy < 0 {
 			p.vy = 0
-			log.Println("Update - Prevented going off top of screen")
+			debugf("Update - Prevented going off top of screen")
 		}
 	}
-	log.Printf("Update - End: onLadder: %v, p.x: %.2f, p.y: %.2f, p.vx: %.2f, p.vy: %.2f",
+	p.updateAnimation()
+	debugf("Update - End: onLadder: %v, p.x: %.2f, p.y: %.2f, p.vx: %.2f, p.vy: %.2f",
 		p.onLadder, p.x, p.y, p.vx, p.vy)
 }
 
-// getCollisionLayer searches for a layer named "Collision" and returns it.
-func getCollisionLayer(layers []Layer) *Layer {
-	for i := range layers {
-		if layers[i].Name == "Collision" {
-			return &layers[i]
+// spriteAt returns the size x size sub-image at a 0-based index in the
+// tilesheet.
+func spriteAt(tiles *ebiten.Image, index, size int) *ebiten.Image {
+	tileXCount := tiles.Bounds().Dx() / size
+	sx := (index % tileXCount) * size
+	sy := (index / tileXCount) * size
+	return tiles.SubImage(image.Rect(sx, sy, sx+size, sy+size)).(*ebiten.Image)
+}
+
+// isDecorLayer reports whether l is a visible tile layer that's only drawn,
+// as opposed to the Collision and Ladders layers the game logic reads.
+// OneWay platforms are drawn like any other layer.
+func isDecorLayer(l *Layer) bool {
+	return l.Type == "tilelayer" && l.Visible && l.Name != "Collision" && l.Name != "Ladders"
+}
+
+// isForegroundLayer reports whether l draws in front of the player: either
+// it's named "Foreground" or it has a true "foreground" property.
+func isForegroundLayer(l *Layer) bool {
+	return l.Name == "Foreground" || l.Properties.Bool("foreground")
+}
+
+// drawTileLayer draws the non-empty tiles of l that are on screen, offset by
+// the camera and honouring Tiled's flip flags.
+func (g *Game) drawTileLayer(screen *ebiten.Image, l *Layer) {
+	x0, y0, x1, y1 := g.camera.visibleTiles(l.Width, l.Height)
+	g.drawTiles(screen, l, x0, y0, x1, y1, g.camera.x, g.camera.y)
+}
+
+// drawTiles draws the tiles of l in columns [x0, x1) and rows [y0, y1) onto
+// dst, shifted left and up by (offX, offY).
+func (g *Game) drawTiles(dst *ebiten.Image, l *Layer, x0, y0, x1, y1 int, offX, offY float64) {
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			i := y*l.Width + x
+			if i >= len(l.Data) {
+				return
+			}
+			g.drawTile(dst, l.Data[i], x, y, offX, offY, l.Opacity)
 		}
 	}
-	return nil
 }
 
-// getLadderLayer searches for a layer named "Ladders" and returns it.
-func getLadderLayer(layers []Layer) *Layer {
+// drawTile draws the tile with raw GID raw in cell (x, y) at the given
+// opacity, showing the current frame if it's animated.
+func (g *Game) drawTile(dst *ebiten.Image, raw, x, y int, offX, offY, opacity float64) {
+	// Tiled GIDs start at 1; 0 means the cell is empty.
+	tile := tileGID(raw)
+	if tile == 0 {
+		return
+	}
+
+	img, src := g.resolveTile(g.tilemap.tiles.animatedGID(tile, g.ticks))
+	if img == nil {
+		return
+	}
+	op := &ebiten.DrawImageOptions{}
+	ts := g.cfg.TileSize
+	op.GeoM = tileFlipGeoM(raw, ts)
+	op.GeoM.Translate(float64(x*ts)-offX, float64(y*ts)-offY)
+	op.ColorScale.ScaleAlpha(float32(opacity))
+	dst.DrawImage(img.SubImage(src).(*ebiten.Image), op)
+}
+
+// tileFlipGeoM returns the transform for a raw GID's flip flags, keeping the
+// tile inside its size x size cell. Tiled applies the diagonal flip (a swap
+// of x and y) first, then the horizontal and vertical flips.
+func tileFlipGeoM(raw, size int) ebiten.GeoM {
+	var m ebiten.GeoM
+	if raw&(flipHorizontal|flipVertical|flipDiagonal) == 0 {
+		return m
+	}
+	half := float64(size) / 2
+	m.Translate(-half, -half)
+	if raw&flipDiagonal != 0 {
+		var swap ebiten.GeoM
+		swap.SetElement(0, 0, 0)
+		swap.SetElement(0, 1, 1)
+		swap.SetElement(1, 0, 1)
+		swap.SetElement(1, 1, 0)
+		m.Concat(swap)
+	}
+	if raw&flipHorizontal != 0 {
+		m.Scale(-1, 1)
+	}
+	if raw&flipVertical != 0 {
+		m.Scale(1, -1)
+	}
+	m.Translate(half, half)
+	return m
+}
+
+// getLayerByName returns the first layer with the given name, or nil.
+func getLayerByName(layers []Layer, name string) *Layer {
 	for i := range layers {
-		if layers[i].Name == "Ladders" {
+		if layers[i].Name == name {
 			return &layers[i]
 		}
 	}
 	return nil
 }
 
+// getCollisionLayer returns the layer named "Collision".
+func getCollisionLayer(layers []Layer) *Layer {
+	return getLayerByName(layers, "Collision")
+}
+
+// getOneWayLayer returns the layer named "OneWay".
+func getOneWayLayer(layers []Layer) *Layer {
+	return getLayerByName(layers, "OneWay")
+}
+
+// getHazardLayer returns the layer named "Hazards".
+func getHazardLayer(layers []Layer) *Layer {
+	return getLayerByName(layers, "Hazards")
+}
+
+// getLadderLayer returns the layer named "Ladders".
+func getLadderLayer(layers []Layer) *Layer {
+	return getLayerByName(layers, "Ladders")
+}
+
 // Game holds the overall game state.
 type Game struct {
-	player Player
+	cfg Config // the sizes and physics the game was built with
+
+	tiles       *ebiten.Image // the tilesheet
+	tilemap     TiledMap      // the current level's map
+	tilesets    []tilesetImage
+	sheets      map[string]*ebiten.Image // tileset images by file name
+	background  []backgroundPass         // the background layers, pre-rendered at level load
+	fullscreen  bool
+	players     []Player // player one first, then anyone who joined
+	shooters    []Shooter
+	enemies     []Enemy
+	projectiles ProjectilePool
+	particles   ParticlePool
+	camera      Camera
+	powerUps    []PowerUp
+	coins       []Coin
+	blocks      []Block
+	score       int
+	lives       int
+	ticks       int // gameplay ticks this run, for the HUD timer
+	state       GameState
+	firstLevel  string        // the level a new run starts on
+	timeLeft    int           // ticks left on the level's countdown
+	zoomed      *ebiten.Image // the camera's view when zoomed, before scaling up
+	minimap     minimap
+	showMinimap bool
+	sound       *Sound
+	settings    Settings
+	watch       levelWatch
+
+	// assetDir is a directory on disk searched for levels and tileset
+	// images before the embedded ones. It's the directory of the -map file,
+	// so the levels and images saved next to it are picked up too.
+	assetDir string
+
+	level       string       // name of the current level
+	transition  *Transition  // active screen transition; gameplay pauses while set
+	warpArmed   bool         // false until every player steps off the warp they arrived on
+	inputs      []InputState // one per player slot, joined or not
+	inputDelays []InputDelay
+
+	checkpoints []Checkpoint
+	triggers    []Trigger
+	keys        []Key
+	doors       []Door
+	heldKeys    map[string]bool // colors of the keys collected on this level
+
+	message      string // text from the last "show message" trigger
+	messageTimer int    // ticks left to show message
+	showDebugHUD bool   // F3 shows the player's physics state on screen
+
+	dayLength, dayClock int // ticks in a day/night cycle, and ticks into the current one
+
+	lighting    lighting
+	lightRadius float64 // the player's light radius from Config, unless a level overrides it
+
+	respawnX, respawnY float64 // where the player respawns: the level start or last checkpoint
 }
 
-func init() {
-	// Decode the embedded tilesheet image.
-	img, _, err := image.Decode(bytes.NewReader(tilesheetBytes))
+// loadAssets decodes the tilesheet and first level map: the embedded ones,
+// or the files named by cfg.TilesPath and cfg.MapPath.
+func loadAssets(cfg Config) (*ebiten.Image, TiledMap, error) {
+	sheet, mapJSON := tilesheetBytes, tilemapJSON
+	if cfg.TilesPath != "" {
+		data, err := os.ReadFile(cfg.TilesPath)
+		if err != nil {
+			return nil, TiledMap{}, fmt.Errorf("reading tilesheet: %w", err)
+		}
+		sheet = data
+	}
+	if cfg.MapPath != "" {
+		data, err := os.ReadFile(cfg.MapPath)
+		if err != nil {
+			return nil, TiledMap{}, fmt.Errorf("reading map: %w", err)
+		}
+		mapJSON = data
+	}
+	img, m, err := decodeAssets(sheet, mapJSON)
 	if err != nil {
-		log.Fatal(err)
+		return nil, m, err
 	}
-	tilesImage = ebiten.NewImageFromImage(img)
+	return ebiten.NewImageFromImage(img), m, nil
+}
 
-	// Decode the embedded JSON tilemap.
-	if err := json.Unmarshal(tilemapJSON, &tilemap); err != nil {
-		log.Fatal(err)
+// decodeAssets does the work of loadAssets on raw bytes. Each way the
+// assets can be broken gets its own message so a bad build is easy to
+// diagnose.
+func decodeAssets(sheet, mapJSON []byte) (image.Image, TiledMap, error) {
+	var m TiledMap
+	if len(sheet) == 0 {
+		return nil, m, errors.New("tilesheet asset is empty; was it embedded?")
+	}
+	img, _, err := image.Decode(bytes.NewReader(sheet))
+	if err != nil {
+		// A readable header with unreadable pixels means the file was cut short.
+		if _, _, cfgErr := image.DecodeConfig(bytes.NewReader(sheet)); cfgErr == nil {
+			return nil, m, fmt.Errorf("tilesheet PNG is truncated or corrupt (%d bytes): %w", len(sheet), err)
+		}
+		return nil, m, fmt.Errorf("tilesheet is not a valid PNG: %w", err)
+	}
+
+	if len(mapJSON) == 0 {
+		return nil, m, errors.New("tilemap asset is empty; was it embedded?")
 	}
+	if err := json.Unmarshal(mapJSON, &m); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, m, fmt.Errorf("tilemap JSON is malformed at byte %d: %w", syntaxErr.Offset, err)
+		}
+		return nil, m, fmt.Errorf("tilemap JSON doesn't match the expected Tiled format: %w", err)
+	}
+	return img, m, nil
+}
+
+// NewGame decodes the assets and returns a game built with cfg,
+// ready to play the first level.
+func NewGame(cfg Config) (*Game, error) {
+	tiles, m, err := loadAssets(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Game{
+		tiles:     tiles,
+		players:   []Player{newPlayer(cfg)},
+		camera:    newCamera(cfg),
+		lives:     startingLives,
+		respawnX:  10,
+		respawnY:  100,
+		inputs:    newInputs(cfg),
+		dayLength: int(cfg.DayLength * ebiten.DefaultTPS),
+	}
+	g.cfg = cfg
+	g.inputDelays = newInputDelays(len(g.inputs), cfg.InputDelay)
+	if cfg.TilesPath != "" {
+		g.sheets = map[string]*ebiten.Image{defaultTilesheet: tiles}
+	}
+	level := "tilemap"
+	if cfg.MapPath != "" {
+		level = strings.TrimSuffix(filepath.Base(cfg.MapPath), filepath.Ext(cfg.MapPath))
+		g.assetDir = filepath.Dir(cfg.MapPath)
+	}
+	g.firstLevel = level
+	g.lightRadius = cfg.LightRadius
+	g.sound = NewSound()
+	g.setLevel(level, m)
+	g.spawnPlayer()
+
+	g.settings = loadSettings()
+	g.fullscreen = g.settings.Fullscreen
+	ebiten.SetFullscreen(g.fullscreen)
+	return g, nil
+}
+
+// newPlayer returns a one-tile player at full health with cfg's physics, at
+// a default starting position used when the map has no PlayerStart object.
+func newPlayer(cfg Config) Player {
+	p := Player{
+		x:         10,
+		y:         100,
+		width:     float64(cfg.TileSize),
+		height:    float64(cfg.TileSize),
+		onGround:  false,
+		onLadder:  false,
+		isJumping: false, // Initialize isJumping to false
+		health:    playerMaxHealth,
+		physics:   cfg.Physics,
+	}
+	p.tileSize = cfg.TileSize
+	return p
+}
+
+// mapSize returns the loaded map's size in pixels.
+func (g *Game) mapSize() (float64, float64) {
+	ts := g.cfg.TileSize
+	return float64(g.tilemap.Width * ts), float64(g.tilemap.Height * ts)
 }
 
 func (g *Game) Update() error {
-	// Toggle fullscreen when "F" is just pressed.
+	paused := false
+	for i := range g.inputs {
+		g.inputs[i].Update()
+		paused = paused || g.inputs[i].PausePressed
+	}
+
+	// Toggle fullscreen when "F" is just pressed, and remember it for next
+	// time.
 	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
-		isFullscreen = !isFullscreen
-		ebiten.SetFullscreen(isFullscreen)
+		g.fullscreen = !g.fullscreen
+		ebiten.SetFullscreen(g.fullscreen)
+		g.settings.Fullscreen = g.fullscreen
+		if err := saveSettings(g.settings); err != nil {
+			log.Printf("settings: %v", err)
+		}
+	}
+
+	g.updateDebugKeys()
+	g.updateZoom()
+	g.updateMinimapKey()
+	g.checkReload()
+
+	// M mutes and unmutes the music and sound effects.
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		g.sound.ToggleMute()
+	}
+
+	// Only StatePlaying runs the world. While paused nothing advances, so
+	// every tick-based timer freezes.
+	switch g.state {
+	case StateMenu:
+		if g.startPressed() {
+			g.startGame()
+		}
+		return nil
+	case StatePaused:
+		if paused {
+			g.state = StatePlaying
+		}
+		return nil
+	case StateGameOver:
+		if g.startPressed() {
+			g.startGame()
+		}
+		return nil
+	}
+	if paused {
+		g.state = StatePaused
+		return nil
+	}
+	g.checkJoin()
+
+	// Inputs pass through the delay buffer before being applied, so local
+	// play can simulate network latency.
+	frames := make([]FrameInput, len(g.players))
+	for i := range frames {
+		frames[i] = g.inputDelays[i].Push(g.inputs[i].Frame)
+	}
+	g.Step(frames)
+	return nil
+}
+
+// updateDebugKeys handles the developer shortcuts. F3 toggles the debug
+// HUD readout. Backquote toggles debug mode, which also shows the collision
+// overlay; while it's on, N toggles no-clip for player one, R reloads the
+// level, and T skips a quarter of the way through the day/night cycle.
+func (g *Game) updateDebugKeys() {
+	collisionLayer := g.tilemap.collisionLayer()
+	p := &g.players[0]
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		g.showDebugHUD = !g.showDebugHUD
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackquote) {
+		debugMode = !debugMode
+		if !debugMode {
+			p.setNoClip(false, collisionLayer)
+		}
+	}
+	if debugMode && inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		g.dayClock += g.dayLength / 4
+	}
+	if debugMode {
+		g.tunePhysics()
+	}
+	if debugMode && inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		p.setNoClip(!p.noClip, collisionLayer)
+	}
+	// [ and ] adjust the no-clip flying speed, one step per press.
+	if p.noClip && inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft) {
+		p.stepNoClipSpeed(-1)
+	}
+	if p.noClip && inpututil.IsKeyJustPressed(ebiten.KeyBracketRight) {
+		p.stepNoClipSpeed(1)
+	}
+	// R reloads the level in debug mode, keeping the player's momentum.
+	if debugMode && inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		kept, err := g.reloadLevel(true)
+		if err != nil {
+			log.Printf("reload: %v", err)
+		} else if !kept {
+			log.Println("reload: map changed shape or player is inside a solid tile; momentum reset")
+		}
+	}
+}
+
+// Step advances the game by one tick using ins as that tick's input, one
+// per player. It doesn't read any device state itself, so replaying the same
+// inputs from the same starting state always gives the same result.
+func (g *Game) Step(ins []FrameInput) {
+	if g.transition != nil {
+		if g.transition.Update() {
+			g.transition = nil
+		}
+		return
+	}
+
+	// Ebiten calls Update at a fixed rate and catches up after slow frames,
+	// so the tick length is 1/TPS. ActualTPS would jitter and break Step's
+	// determinism.
+	dt := 1 / float64(ebiten.TPS())
+	g.ticks++
+	g.dayClock++
+	if g.timeLeft > 0 {
+		g.timeLeft--
+		if g.timeLeft == 0 {
+			debugf("Update - Out of time")
+			for i := range g.players {
+				g.players[i].Kill()
+			}
+		}
 	}
 
 	// Get the collision layer (if available).
-	collisionLayer := getCollisionLayer(tilemap.Layers)
+	collisionLayer := g.tilemap.collisionLayer()
 	// Get the ladder layer (if available).
-	ladderLayer := getLadderLayer(tilemap.Layers)
+	ladderLayer := getLadderLayer(g.tilemap.Layers)
 
-	// Update the player with collision and ladder checking.
-	if ladderLayer != nil {
-		g.player.Update(collisionLayer, ladderLayer)
-	} else {
-		g.player.Update(collisionLayer, nil) // Pass nil if no ladder layer
+	physics := g.players[0].physics
+	updateBlocks(g.blocks, collisionLayer, float64(g.cfg.TileSize), physics.Gravity, physics.MaxFall, tickScale(dt))
+
+	// Update each player with collision and ladder checking. They share
+	// the level but don't collide with each other.
+	for i := range g.players {
+		p := &g.players[i]
+		var in FrameInput
+		if i < len(ins) {
+			in = ins[i]
+		}
+		fromX, fromY := p.x, p.y
+		p.Update(collisionLayer, ladderLayer, getOneWayLayer(g.tilemap.Layers), getWaterLayer(g.tilemap.Layers), getRopeLayer(g.tilemap.Layers), getBreakableLayer(g.tilemap.Layers), g.blocks, in, dt)
+		g.leashPlayer(i, fromX, fromY)
+		if p.brokeTile {
+			g.buildBackground()
+		}
+		p.checkHazards(getHazardLayer(g.tilemap.Layers))
+		g.attack(p)
+	}
+	g.updateEnemies(collisionLayer, physics.Gravity, physics.MaxFall, tickScale(dt))
+
+	for i := range g.shooters {
+		g.shooters[i].Update(g.players, &g.projectiles, float64(g.cfg.TileSize), tickScale(dt))
+	}
+	g.projectiles.Update(collisionLayer, g.players, float64(g.cfg.TileSize), tickScale(dt))
+	for i := range g.players {
+		p := &g.players[i]
+		collectPowerUps(g.powerUps, p)
+		if points := collectCoins(g.coins, p, &g.particles); points > 0 {
+			g.score += points
+			g.sound.Play(SoundCoin)
+		}
+		if p.jumped {
+			g.sound.Play(SoundJump)
+		}
+		if p.landed {
+			g.sound.Play(SoundLand)
+			if p.landSpeed >= dustMinSpeed {
+				g.particles.Dust(p.x+p.width/2, p.y+p.height)
+			}
+		}
+	}
+	g.particles.Update()
+	g.rumble()
+	g.shake()
+	g.checkWarps()
+	g.checkTeleports()
+	g.checkExits()
+	g.checkCheckpoints()
+	g.checkTriggers()
+	g.checkKeysAndDoors()
+
+	mapW, mapH := g.mapSize()
+	g.camera.Update(g.players, mapW, mapH)
+	died := false
+	for i := range g.players {
+		p := &g.players[i]
+		if g.camera.autoScroll != 0 && g.camera.pushPlayer(p, collisionLayer) {
+			debugf("Update - Crushed by the screen edge")
+			p.Kill()
+		}
+
+		// Falling out of the bottom of the map is fatal.
+		if !p.noClip && p.y > g.killY() {
+			p.health = 0
+		}
+		died = died || p.health <= 0
+	}
+	// Players dying on the same tick share one life.
+	if died {
+		g.lives = max(g.lives-1, 0)
+		if g.lives == 0 {
+			g.state = StateGameOver
+		} else {
+			g.respawn()
+		}
 	}
-	return nil
+}
+
+// attack removes any enemies caught in p's melee hitbox.
+func (g *Game) attack(p *Player) {
+	hx, hy, hw, hh, ok := p.attackHitbox()
+	if !ok {
+		return
+	}
+	ts := float64(g.cfg.TileSize)
+	alive := g.shooters[:0]
+	for _, s := range g.shooters {
+		if !aabbOverlap(hx, hy, hw, hh, s.x, s.y, ts, ts) {
+			alive = append(alive, s)
+		}
+	}
+	g.shooters = alive
+
+	enemies := g.enemies[:0]
+	for _, e := range g.enemies {
+		if !aabbOverlap(hx, hy, hw, hh, e.x, e.y, ts, ts) {
+			enemies = append(enemies, e)
+		}
+	}
+	g.enemies = enemies
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
+	// The world is drawn 1:1 into the camera's view, then scaled up to fill
+	// the screen when zoomed. The HUD goes on top at screen scale.
+	screen.Fill(image.Black)
+	world := g.zoomTarget(screen)
+	// Shaking nudges the camera for this frame only.
+	camX, camY := g.camera.x, g.camera.y
+	dx, dy := g.camera.shakeOffset()
+	g.camera.x, g.camera.y = camX+dx, camY+dy
+	g.drawWorld(world)
+	g.camera.x, g.camera.y = camX, camY
+	g.drawAmbientTint(world)
+	g.drawLighting(world)
+	if world != screen {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(float64(g.camera.zoom), float64(g.camera.zoom))
+		screen.DrawImage(world, op)
+	}
+
+	switch g.state {
+	case StateMenu:
+		g.drawMenu(screen)
+		return
+	case StateGameOver:
+		g.drawGameOver(screen)
+		return
+	}
+
+	g.drawHUD(screen)
+	drawEffectTimers(screen, g.players)
+	g.drawMessage(screen)
+	if g.showDebugHUD {
+		g.drawDebugHUD(screen)
+	}
+	g.drawMinimap(screen)
+	drawTouchControls(screen, &g.inputs[0])
+
+	if g.transition != nil {
+		g.transition.Draw(screen)
+	}
+	if g.state == StatePaused {
+		drawPauseOverlay(screen)
+	}
+}
+
+// drawWorld draws the level and everything in it, offset by the camera.
+func (g *Game) drawWorld(screen *ebiten.Image) {
 	// Fill the background with black.
 	screen.Fill(image.Black)
 
-	// Draw the tilemap background (assume the first layer is the visible background).
-	if len(tilemap.Layers) > 0 {
-		bgLayer := tilemap.Layers[0]
-		tilesheetWidth := tilesImage.Bounds().Dx()
-		tileXCount := tilesheetWidth / tileSize
+	// Draw the far-background layers, then the decorative tile layers that
+	// sit behind the player.
+	g.drawParallaxLayers(screen)
+	g.drawBackground(screen)
 
-		for i, tile := range bgLayer.Data {
-			// Tiled exports tile indices starting at 1 (0 means empty), so adjust.
-			if tile == 0 {
-				continue
-			}
-			tile-- // convert to 0-based index
+	// Draw ladders from the logic layer with their proper cap sprites.
+	g.drawLadderLayer(screen, getLadderLayer(g.tilemap.Layers))
 
-			x := i % bgLayer.Width
-			y := i / bgLayer.Width
+	drawPowerUps(screen, g.tiles, g.powerUps, &g.camera)
+	drawCoins(screen, g.tiles, g.coins, &g.camera)
+	drawCheckpoints(screen, g.tiles, g.checkpoints, &g.camera)
+	drawBlocks(screen, g.tiles, g.blocks, &g.camera)
+	drawDoors(screen, g.tiles, g.doors, &g.camera)
+	drawKeys(screen, g.tiles, g.keys, &g.camera)
+	for i := range g.shooters {
+		g.shooters[i].Draw(screen, g.tiles, &g.camera)
+	}
+	for i := range g.enemies {
+		g.enemies[i].Draw(screen, g.tiles, &g.camera)
+	}
+	g.projectiles.Draw(screen, &g.camera)
+	g.particles.Draw(screen, &g.camera)
 
-			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(float64(x*tileSize), float64(y*tileSize))
+	// Draw the players.
+	for i := range g.players {
+		g.drawPlayer(screen, &g.players[i], playerTints[i%len(playerTints)])
+	}
 
-			sx := (tile % tileXCount) * tileSize
-			sy := (tile / tileXCount) * tileSize
-			subImage := tilesImage.SubImage(
-				image.Rect(sx, sy, sx+tileSize, sy+tileSize),
-			).(*ebiten.Image)
-			screen.DrawImage(subImage, op)
+	// Draw the layers that cover the player, e.g. vines and pillar tops.
+	for i := range g.tilemap.Layers {
+		if l := &g.tilemap.Layers[i]; isDecorLayer(l) && isForegroundLayer(l) {
+			g.drawTileLayer(screen, l)
 		}
 	}
 
-	// Draw the player.
-	const playerSpriteIndex = 280
-	tilesheetWidth := tilesImage.Bounds().Dx()
-	tileXCount := tilesheetWidth / tileSize
-	sx := (playerSpriteIndex % tileXCount) * tileSize
-	sy := (playerSpriteIndex / tileXCount) * tileSize
-	playerImage := tilesImage.SubImage(
-		image.Rect(sx, sy, sx+tileSize, sy+tileSize),
+	// Draw the melee swipes.
+	for i := range g.players {
+		if hx, hy, hw, hh, ok := g.players[i].attackHitbox(); ok {
+			vector.DrawFilledRect(screen, float32(hx-g.camera.x), float32(hy-g.camera.y), float32(hw), float32(hh), color.RGBA{0xff, 0xff, 0xff, 0x80}, false)
+		}
+	}
+
+	if debugMode {
+		g.drawDebugOverlay(screen)
+	}
+}
+
+// playerTints colors each player's sprite so they can be told apart:
+// player one red, player two blue.
+var playerTints = [][3]float32{
+	{1, 0, 0},
+	{0.3, 0.5, 1},
+}
+
+// drawPlayer draws p's current animation frame, tinted, facing the way
+// they last moved, and flashing while invulnerable.
+func (g *Game) drawPlayer(screen *ebiten.Image, p *Player, tint [3]float32) {
+	ts := g.cfg.TileSize
+	spriteIndex := p.spriteIndex()
+	tilesheetWidth := g.tiles.Bounds().Dx()
+	tileXCount := tilesheetWidth / ts
+	sx := (spriteIndex % tileXCount) * ts
+	sy := (spriteIndex / tileXCount) * ts
+	playerImage := g.tiles.SubImage(
+		image.Rect(sx, sy, sx+ts, sy+ts),
 	).(*ebiten.Image)
 
 	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(g.player.x, g.player.y)
-	op.ColorScale.Scale(1, 0, 0, 1)
+	if p.facingLeft {
+		// Mirror the sprite within its own tile so it stays in place.
+		op.GeoM.Scale(-1, 1)
+		op.GeoM.Translate(float64(ts), 0)
+	}
+	op.GeoM.Scale(p.width/float64(ts), p.height/float64(ts))
+	op.GeoM.Translate(p.x-g.camera.x, p.y-g.camera.y)
+	op.ColorScale.Scale(tint[0], tint[1], tint[2], 1)
+	if p.invulnTimer > 0 && p.invulnTimer/4%2 == 0 {
+		// Flash while invulnerable.
+		op.ColorScale.ScaleAlpha(0.25)
+	}
 	screen.DrawImage(playerImage, op)
-	// ebitenutil.DebugPrint(screen, fmt.Sprintf("TPS: %0.2f", ebiten.ActualTPS()))
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return screenWidth, screenHeight
+	return g.cfg.ScreenWidth, g.cfg.ScreenHeight
 }
 
 func main() {
-	// Initialize the player starting position and size.
-	game := &Game{
-		player: Player{
-			x:         10,
-			y:         100,
-			width:     tileSize,
-			height:    tileSize,
-			onGround:  false,
-			onLadder:  false,
-			isJumping: false, // Initialize isJumping to false
-		},
-	}
-
-	ebiten.SetWindowSize(screenWidth*2, screenHeight*2)
-	ebiten.SetWindowTitle("Player with Collision and Ladders")
+	cfg := DefaultConfig()
+	flag.BoolVar(&debugLog, "debug", false, "log per-tick physics and ladder diagnostics")
+	flag.StringVar(&cfg.MapPath, "map", "", "load the first level from this Tiled JSON `file` instead of the embedded one")
+	flag.StringVar(&cfg.TilesPath, "tiles", "", "load the default tilesheet from this PNG `file` instead of the embedded one")
+	flag.Float64Var(&cfg.DayLength, "daylength", cfg.DayLength, "`seconds` in a day/night cycle; 0 turns it off")
+	flag.IntVar(&cfg.InputDelay, "inputdelay", cfg.InputDelay, "hold input back this many `ticks`, to simulate netplay latency")
+	flag.Func("collision", "comma-separated `tilesets` whose tiles are solid on maps without a Collision layer", func(s string) error {
+		for _, name := range strings.Split(s, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.CollisionTilesets = append(cfg.CollisionTilesets, name)
+			}
+		}
+		return nil
+	})
+	flag.Parse()
+
+	game, err := NewGame(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Couldn't load the game assets: %v\n", err)
+		os.Exit(1)
+	}
+
+	ebiten.SetWindowSize(cfg.ScreenWidth*2, cfg.ScreenHeight*2)
+	title := "Player with Collision and Ladders"
+	if name := game.tilemap.StringProperty("name"); name != "" {
+		title = name
+	}
+	ebiten.SetWindowTitle(title)
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testLayer builds a tile layer from rows of text, one character per tile:
+// '.' is empty and anything else is a tile with GID 1.
+func testLayer(rows ...string) *Layer {
+	l := &Layer{Name: "Collision", Type: "tilelayer", Width: len(rows[0]), Height: len(rows)}
+	for _, row := range rows {
+		for _, c := range row {
+			gid := 0
+			if c != '.' {
+				gid = 1
+			}
+			l.Data = append(l.Data, gid)
+		}
+	}
+	return l
+}
+
+// testPlayer returns a default, one-tile player at (x, y).
+func testPlayer(x, y float64) Player {
+	p := newPlayer(DefaultConfig())
+	p.x, p.y = x, y
+	return p
+}
+
+func TestCollides(t *testing.T) {
+	collision := testLayer(
+		"##..",
+		"....",
+		"..#.",
+		"####",
+	)
+	short := testLayer(
+		"##..",
+		"....",
+		"..#.",
+		"####",
+	)
+	short.Data = short.Data[:14] // the last two tiles of the bottom row are missing
+
+	tests := []struct {
+		name      string
+		collision *Layer
+		x, y      float64
+		want      bool
+	}{
+		{"resting on the floor", collision, 0, 32, false},
+		{"into the floor", collision, 0, 32.5, true},
+		{"beside a wall", collision, 16, 32, false},
+		{"into a wall", collision, 16.5, 32, true},
+		{"under the ceiling", collision, 0, 16, false},
+		{"into the ceiling", collision, 0, 15.5, true},
+		{"corner only", collision, 16.5, 16.5, true},
+		{"just clear of the corner", collision, 16, 16.5, false},
+		{"hanging off the left edge", collision, -8, 16, false},
+		{"past the right edge", collision, 64, 16, false},
+		{"above the top edge", collision, 32, -20, false},
+		{"no layer", nil, 0, 40, false},
+		{"short data still checks the tiles it has", short, 32, 40, true},
+		{"short data skips the missing tiles", short, 48, 40, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPlayer(tt.x, tt.y)
+			if got := p.collides(tt.x, tt.y, tt.collision); got != tt.want {
+				t.Errorf("collides(%v, %v) = %v, want %v", tt.x, tt.y, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollidesOneWay(t *testing.T) {
+	collision := testLayer(
+		"....",
+		"####",
+	)
+	collision.tiles = &levelTiles{oneWay: map[int]bool{1: true}}
+	p := testPlayer(0, 8)
+	p.vy = -1
+	if p.collides(p.x, p.y, collision) {
+		t.Error("one-way tile blocks a player moving up")
+	}
+	p.vy = 1
+	if !p.collides(p.x, p.y, collision) {
+		t.Error("one-way tile doesn't block a player moving down")
+	}
+}
+
+func TestCollidesAtMapEdge(t *testing.T) {
+	collision := testLayer(
+		"#..#",
+		"#..#",
+	)
+	tests := []struct {
+		name string
+		x    float64
+	}{
+		{"hanging off the left edge", -8},
+		{"hanging off the right edge", 56},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPlayer(tt.x, 0)
+			if !p.collides(tt.x, 0, collision) {
+				t.Error("box half off the map passes through the solid edge tile it overlaps")
+			}
+		})
+	}
+}
+
+func TestMeleeAttack(t *testing.T) {
+	tests := []struct {
+		name       string
+		facingLeft bool
+		targetX    float64
+		wantKilled bool
+	}{
+		{"in front", false, 30, true},
+		{"out of reach in front", false, 48, false},
+		{"behind", false, 0, false},
+		{"in front facing left", true, 0, true},
+	}
+	// Each case swings at a ranged shooter and at a patrolling enemy.
+	targets := []struct {
+		name   string
+		place  func(g *Game, x float64)
+		killed func(g *Game) bool
+	}{
+		{
+			"shooter",
+			func(g *Game, x float64) { g.shooters = []Shooter{{x: x, y: 0, cooldown: shooterInterval}} },
+			func(g *Game) bool { return len(g.shooters) == 0 },
+		},
+		{
+			"enemy",
+			func(g *Game, x float64) {
+				g.enemies = []Enemy{{x: x, y: 0, vx: enemySpeed, minX: x - enemyPatrolRange, maxX: x + enemyPatrolRange}}
+			},
+			func(g *Game) bool { return len(g.enemies) == 0 },
+		},
+	}
+	for _, tt := range tests {
+		for _, target := range targets {
+			t.Run(tt.name+"/"+target.name, func(t *testing.T) {
+				g := testLevel()
+				g.players[0].x, g.players[0].y = 16, 0
+				g.players[0].facingLeft = tt.facingLeft
+				g.players[0].attackTimer = attackDuration
+				target.place(g, tt.targetX)
+				g.Step([]FrameInput{{}})
+				if killed := target.killed(g); killed != tt.wantKilled {
+					t.Errorf("%s at x = %v killed = %v, want %v", target.name, tt.targetX, killed, tt.wantKilled)
+				}
+			})
+		}
+	}
+}
+
+func TestAttackHitboxExpires(t *testing.T) {
+	p := testPlayer(0, 0)
+	if _, _, _, _, ok := p.attackHitbox(); ok {
+		t.Error("hitbox out without attacking")
+	}
+	p.attackTimer = 1
+	if _, _, _, _, ok := p.attackHitbox(); !ok {
+		t.Error("no hitbox on the last tick of an attack")
+	}
+}
+
+// stepPlayer runs ticks reference ticks of p.Update with in held, calling
+// check after each one.
+func stepPlayer(p *Player, collision, ladders *Layer, in FrameInput, ticks int, check func(tick int)) {
+	for i := range ticks {
+		p.Update(collision, ladders, nil, nil, nil, nil, nil, in, 1.0/referenceTPS)
+		if check != nil {
+			check(i)
+		}
+	}
+}
+
+func TestJumpIntoCeilingDoesNotEmbed(t *testing.T) {
+	collision := testLayer(
+		"....",
+		"####",
+		"....",
+		"....",
+		"####",
+	)
+	ceiling := 32.0
+	p := testPlayer(0, 48)
+	stepPlayer(&p, collision, nil, FrameInput{}, 10, nil)
+	if !p.onGround {
+		t.Fatal("player didn't settle on the floor")
+	}
+
+	hit := false
+	stepPlayer(&p, collision, nil, FrameInput{Jump: true, JumpPressed: true}, 1, nil)
+	stepPlayer(&p, collision, nil, FrameInput{Jump: true}, 60, func(tick int) {
+		if p.y < ceiling || p.collides(p.x, p.y, collision) {
+			t.Fatalf("tick %d: player embedded in the ceiling at y = %v", tick, p.y)
+		}
+		if p.y == ceiling {
+			hit = true
+			if p.vy < 0 {
+				t.Fatalf("tick %d: still moving up (vy = %v) against the ceiling", tick, p.vy)
+			}
+		}
+	})
+	if !hit {
+		t.Error("the jump never reached the ceiling")
+	}
+	if !p.onGround || p.y != 48 {
+		t.Errorf("player ended at y = %v, onGround %v; want back on the floor at 48", p.y, p.onGround)
+	}
+}
+
+// TestFastFallLandsOnPlatform drops the player onto a one-tile-thick
+// platform faster than a tile per tick, once in a single reference tick and
+// once in a long tick that Move has to split into substeps.
+func TestFastFallLandsOnPlatform(t *testing.T) {
+	collision := testLayer(
+		"....",
+		"....",
+		"....",
+		"....",
+		"....",
+		"####",
+		"....",
+		"....",
+	)
+	top := 80.0
+	tests := []struct {
+		name string
+		y    float64
+		k    float64 // reference ticks in the Move
+	}{
+		{"one tick", 48, 1},
+		{"substepped long tick", 0, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPlayer(0, tt.y)
+			p.vy = 24
+			p.Move(collision, nil, nil, nil, nil, tt.k)
+			if !p.onGround || p.y != top-p.height {
+				t.Errorf("player at y = %v, onGround %v; want standing on the platform at %v", p.y, p.onGround, top-p.height)
+			}
+		})
+	}
+}
+
+// TestSlowWalkStopsCleanlyAtWall walks into a wall at 0.3px a tick from a
+// fractional position, which used to make the tile lookup jitter.
+func TestSlowWalkStopsCleanlyAtWall(t *testing.T) {
+	collision := testLayer(
+		"...#",
+		"####",
+	)
+	p := testPlayer(1.1, 0)
+	p.physics.Speed = 0.3
+	wall := 48.0
+	prev := p.x
+	stepPlayer(&p, collision, nil, FrameInput{Right: true}, 200, func(tick int) {
+		if p.x < prev {
+			t.Fatalf("tick %d: x went back from %v to %v", tick, prev, p.x)
+		}
+		if p.x+p.width > wall {
+			t.Fatalf("tick %d: player at x = %v overlaps the wall", tick, p.x)
+		}
+		prev = p.x
+	})
+	if p.x+p.width != wall {
+		t.Errorf("player stopped at x = %v, want flush against the wall at %v", p.x, wall-p.width)
+	}
+}
+
+func TestCollidesTallPlayer(t *testing.T) {
+	collision := testLayer(
+		"....",
+		"....",
+		"....",
+		"#...",
+	)
+	p := testPlayer(0, 0)
+	p.Resize(16, 32)
+	tests := []struct {
+		name string
+		y    float64
+		want bool
+	}{
+		{"spanning the two rows above the tile", 16, false},
+		{"reaching into the tile", 16.5, true},
+		{"spanning three rows, clear of the tile", 0.5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.collides(0, tt.y, collision); got != tt.want {
+				t.Errorf("collides(0, %v) for a 32px-tall player = %v, want %v", tt.y, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResizeKeepsFeetAndCenter(t *testing.T) {
+	p := testPlayer(32, 32)
+	p.Resize(8, 32)
+	if p.x != 36 || p.y != 16 {
+		t.Errorf("resized player at (%v, %v), want (36, 16) with the same feet and center", p.x, p.y)
+	}
+}
+
+// jumpHeight jumps p from the floor of an empty room and returns how high
+// their feet got.
+func jumpHeight(p Player) float64 {
+	collision := testLayer(
+		"..........",
+		"..........",
+		"..........",
+		"..........",
+		"..........",
+		"..........",
+		"..........",
+		"..........",
+		"..........",
+		"##########",
+	)
+	p.x, p.y = 16, 144-p.height
+	floor := p.y
+	stepPlayer(&p, collision, nil, FrameInput{Jump: true, JumpPressed: true}, 1, nil)
+	apex := p.y
+	stepPlayer(&p, collision, nil, FrameInput{Jump: true}, 120, func(int) { apex = min(apex, p.y) })
+	return floor - apex
+}
+
+func TestJumpScalesWithSize(t *testing.T) {
+	small := testPlayer(0, 0)
+	tall := testPlayer(0, 0)
+	tall.Resize(16, 32)
+	hs, ht := jumpHeight(small), jumpHeight(tall)
+	if ratio := ht / hs; ratio < 1.9 || ratio > 2.1 {
+		t.Errorf("a 2-tile player jumps %vpx and a 1-tile one %vpx, a ratio of %.2f; want about 2", ht, hs, ratio)
+	}
+}
+
+// TestResolveOrderAtCorner moves the player diagonally into the corner of a
+// tile, where the axis resolved first is the one that gets to move.
+func TestResolveOrderAtCorner(t *testing.T) {
+	collision := testLayer(
+		"....",
+		".#..",
+		"....",
+	)
+	tests := []struct {
+		name         string
+		order        ResolveOrder
+		vx, vy       float64
+		wantX, wantY float64
+	}{
+		{"x then y", ResolveXThenY, 4, 4, 4, 0},
+		{"y then x", ResolveYThenX, 4, 4, 0, 4},
+		{"larger first, mostly sideways", ResolveLargerFirst, 4, 3, 4, 0},
+		{"larger first, mostly down", ResolveLargerFirst, 3, 4, 0, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPlayer(0, 0)
+			p.physics.ResolveOrder = tt.order
+			p.vx, p.vy = tt.vx, tt.vy
+			p.Move(collision, nil, nil, nil, nil, 1)
+			if p.x != tt.wantX || p.y != tt.wantY {
+				t.Errorf("ended at (%v, %v), want (%v, %v)", p.x, p.y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+// TestStepDeterministic runs the same inputs through two identical games and
+// checks they end up in the same state, and that delaying the inputs only
+// shifts the result in time.
+func TestStepDeterministic(t *testing.T) {
+	newGame := func() *Game {
+		g := testLevel()
+		floor := Layer{Name: "Collision", Type: "tilelayer", Width: 20, Height: 10, Data: make([]int, 200)}
+		for tx := range 20 {
+			floor.Data[9*20+tx] = 1
+		}
+		g.tilemap.Layers = append(g.tilemap.Layers, floor)
+		g.tilemap.attachTiles(nil)
+		return g
+	}
+	var script []FrameInput
+	for i := range 120 {
+		in := FrameInput{Right: i < 60, Left: i >= 90, Jump: i >= 20 && i < 40, JumpPressed: i == 20, JumpReleased: i == 40}
+		in.DashPressed = i == 70
+		script = append(script, in)
+	}
+
+	a, b := newGame(), newGame()
+	for _, in := range script {
+		a.Step([]FrameInput{in})
+		b.Step([]FrameInput{in})
+	}
+	if a.players[0].x == 16 {
+		t.Fatal("the script didn't move the player")
+	}
+	if !reflect.DeepEqual(a.players, b.players) {
+		t.Errorf("same inputs gave different players:\n%+v\n%+v", a.players[0], b.players[0])
+	}
+
+	const delay = 3
+	c := newGame()
+	d := InputDelay{frames: delay}
+	for _, in := range append(script, make([]FrameInput, delay)...) {
+		c.Step([]FrameInput{d.Push(in)})
+	}
+	shifted := newGame()
+	for _, in := range append(make([]FrameInput, delay), script...) {
+		shifted.Step([]FrameInput{in})
+	}
+	if !reflect.DeepEqual(c.players, shifted.players) {
+		t.Errorf("delayed inputs differ from the same inputs %d ticks later:\n%+v\n%+v", delay, c.players[0], shifted.players[0])
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// minimapMaxSize caps the minimap's longer side in screen pixels. Maps
+// larger than this many tiles are shrunk so each pixel covers a block of
+// tiles.
+const minimapMaxSize = 48
+
+var (
+	minimapBackColor   = color.RGBA{0, 0, 0, 0xa0}
+	minimapSolidColor  = color.RGBA{0xa0, 0xa0, 0xa0, 0xff}
+	minimapPlayerColor = color.RGBA{0xff, 0x40, 0x40, 0xff}
+)
+
+// minimap is a tiny rendering of the current level's collision layer.
+type minimap struct {
+	image *ebiten.Image
+	scale int // tiles per minimap pixel
+}
+
+// buildMinimap downsamples collision to at most minimapMaxSize pixels on a
+// side. A pixel is filled if any tile in its block is solid.
+func buildMinimap(collision *Layer) minimap {
+	if collision == nil || collision.Width <= 0 || collision.Height <= 0 {
+		return minimap{}
+	}
+	scale := (max(collision.Width, collision.Height) + minimapMaxSize - 1) / minimapMaxSize
+	w := (collision.Width + scale - 1) / scale
+	h := (collision.Height + scale - 1) / scale
+	img := ebiten.NewImage(w, h)
+	img.Fill(minimapBackColor)
+	for ty := range collision.Height {
+		for tx := range collision.Width {
+			if collision.tileAt(tx, ty) != 0 {
+				img.Set(tx/scale, ty/scale, minimapSolidColor)
+			}
+		}
+	}
+	return minimap{image: img, scale: scale}
+}
+
+// updateMinimapKey toggles the minimap with Tab.
+func (g *Game) updateMinimapKey() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		g.showMinimap = !g.showMinimap
+	}
+}
+
+// drawMinimap draws the minimap in the bottom-right corner with a dot for
+// each player. It's in screen space, so the camera doesn't move it.
+func (g *Game) drawMinimap(screen *ebiten.Image) {
+	mm := g.minimap
+	if !g.showMinimap || mm.image == nil {
+		return
+	}
+	b := mm.image.Bounds()
+	x, y := HUDAnchor{Anchor: BottomRight, OffsetX: 2, OffsetY: 2}.Position(float64(b.Dx()), float64(b.Dy()), screen.Bounds().Dx(), screen.Bounds().Dy())
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(x, y)
+	screen.DrawImage(mm.image, op)
+
+	for _, p := range g.players {
+		px := x + (p.x+p.width/2)/float64(g.cfg.TileSize*mm.scale)
+		py := y + (p.y+p.height/2)/float64(g.cfg.TileSize*mm.scale)
+		vector.DrawFilledRect(screen, float32(px)-1, float32(py)-1, 2, 2, minimapPlayerColor, false)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import "log"
+
+const (
+	noClipDefaultSpeed = 3.0
+	noClipMinSpeed     = 0.5
+	noClipMaxSpeed     = 12.0
+	noClipSpeedStep    = 0.5
+)
+
+// updateNoClip flies the player with the movement input, ignoring gravity,
+// ladders, and collision entirely.
+func (p *Player) updateNoClip(in FrameInput, k float64) {
+	p.vx, p.vy = 0, 0
+	if in.Left {
+		p.vx = -p.noClipSpeed
+		p.facingLeft = true
+	} else if in.Right {
+		p.vx = p.noClipSpeed
+		p.facingLeft = false
+	}
+	if in.Up {
+		p.vy = -p.noClipSpeed
+	} else if in.Down {
+		p.vy = p.noClipSpeed
+	}
+	p.x += p.vx * k
+	p.y += p.vy * k
+}
+
+// stepNoClipSpeed makes the flying speed one step faster (dir > 0) or
+// slower (dir < 0), within its limits.
+func (p *Player) stepNoClipSpeed(dir float64) {
+	p.noClipSpeed = min(max(p.noClipSpeed+dir*noClipSpeedStep, noClipMinSpeed), noClipMaxSpeed)
+}
+
+// setNoClip turns free-fly mode on or off. Turning it off restores normal
+// physics and pushes the player out of any solid tiles they were left in.
+func (p *Player) setNoClip(on bool, collision *Layer) {
+	if p.noClip == on {
+		return
+	}
+	p.noClip = on
+	p.vx, p.vy = 0, 0
+	p.onLadder = false
+	p.onRope = false
+	p.onGround = false
+	if on {
+		if p.noClipSpeed == 0 {
+			p.noClipSpeed = noClipDefaultSpeed
+		}
+		log.Println("No-clip on")
+		return
+	}
+	p.ejectFromSolids(collision)
+	log.Println("No-clip off")
+}
+
+// ejectFromSolids moves the player to the nearest free spot, searching
+// straight up first and then outward to the sides, one pixel at a time.
+func (p *Player) ejectFromSolids(collision *Layer) {
+	if collision == nil || !p.collides(p.x, p.y, collision) {
+		return
+	}
+	maxDist := 4 * float64(p.tileSize)
+	for d := 1.0; d <= maxDist; d++ {
+		for _, off := range [][2]float64{{0, -d}, {-d, 0}, {d, 0}, {0, d}} {
+			if !p.collides(p.x+off[0], p.y+off[1], collision) {
+				p.x += off[0]
+				p.y += off[1]
+				return
+			}
+		}
+	}
+}
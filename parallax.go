@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// defaultParallax is how fast a "BG_" layer without a "parallax" property
+// scrolls relative to the camera.
+const defaultParallax = 0.5
+
+// isParallaxLayer reports whether l is a far-background layer, marked by a
+// "BG_" name prefix. These are drawn behind everything else and scroll at
+// their own rate.
+func isParallaxLayer(l *Layer) bool {
+	return isDecorLayer(l) && strings.HasPrefix(l.Name, "BG_")
+}
+
+// parallaxFactor is the fraction of the camera's horizontal movement l
+// follows, from its "parallax" property: 0.5 scrolls at half speed and 1
+// moves with the rest of the map.
+func parallaxFactor(l *Layer) float64 {
+	return l.Properties.Float("parallax", defaultParallax)
+}
+
+// drawParallaxLayers draws every "BG_" layer, offset horizontally by the
+// camera scaled by its parallax factor.
+func (g *Game) drawParallaxLayers(screen *ebiten.Image) {
+	for i := range g.tilemap.Layers {
+		l := &g.tilemap.Layers[i]
+		if !isParallaxLayer(l) {
+			continue
+		}
+		view := g.camera
+		view.x *= parallaxFactor(l)
+		x0, y0, x1, y1 := view.visibleTiles(l.Width, l.Height)
+		g.drawTiles(screen, l, x0, y0, x1, y1, view.x, view.y)
+	}
+}
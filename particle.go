@@ -0,0 +1,126 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	particleSize    = 2.0
+	particleGravity = 0.05
+
+	dustCount       = 6
+	dustLifetime    = 18  // ticks
+	dustMinSpeed    = 3.0 // how fast the player has to land to kick up dust
+	sparkleCount    = 8
+	sparkleSpeed    = 1.0
+	sparkleLifetime = 24 // ticks
+)
+
+var (
+	dustColor    = color.RGBA{0xc0, 0xc0, 0xc0, 0xff}
+	sparkleColor = color.RGBA{0xff, 0xe0, 0x40, 0xff}
+)
+
+// pixel is a single white pixel, scaled and tinted through GeoM and
+// ColorScale to draw particles and full-screen tints. See whitePixel.
+var pixel *ebiten.Image
+
+// whitePixel returns pixel, creating it on first use.
+func whitePixel() *ebiten.Image {
+	if pixel == nil {
+		pixel = ebiten.NewImage(1, 1)
+		pixel.Fill(color.White)
+	}
+	return pixel
+}
+
+// Particle is a short-lived speck of decoration with no effect on play.
+type Particle struct {
+	x, y    float64
+	vx, vy  float64
+	life    int // remaining ticks
+	total   int // ticks it started with, for the fade
+	gravity float64
+	clr     color.RGBA
+	active  bool
+}
+
+// ParticlePool owns every particle in the level. Like ProjectilePool, dead
+// particles' slots are reused.
+type ParticlePool struct {
+	items []Particle
+}
+
+// Spawn adds pt to the pool, starting its fade from its life.
+func (pp *ParticlePool) Spawn(pt Particle) {
+	pt.active = true
+	pt.total = pt.life
+	for i := range pp.items {
+		if !pp.items[i].active {
+			pp.items[i] = pt
+			return
+		}
+	}
+	pp.items = append(pp.items, pt)
+}
+
+// Dust kicks up a puff from the ground under (x, y), spread evenly to
+// either side. The pattern is fixed so Step stays deterministic.
+func (pp *ParticlePool) Dust(x, y float64) {
+	for i := range dustCount {
+		f := float64(i)/float64(dustCount-1)*2 - 1 // -1 to 1
+		pp.Spawn(Particle{
+			x: x, y: y - particleSize,
+			vx: f, vy: -0.3 - 0.3*(1-math.Abs(f)),
+			life: dustLifetime, gravity: particleGravity, clr: dustColor,
+		})
+	}
+}
+
+// Sparkle bursts a ring of particles out from (x, y).
+func (pp *ParticlePool) Sparkle(x, y float64) {
+	for i := range sparkleCount {
+		a := 2 * math.Pi * float64(i) / sparkleCount
+		pp.Spawn(Particle{
+			x: x, y: y,
+			vx: math.Cos(a) * sparkleSpeed, vy: math.Sin(a) * sparkleSpeed,
+			life: sparkleLifetime, clr: sparkleColor,
+		})
+	}
+}
+
+// Update moves every active particle and retires the expired ones.
+func (pp *ParticlePool) Update() {
+	for i := range pp.items {
+		pt := &pp.items[i]
+		if !pt.active {
+			continue
+		}
+		pt.life--
+		if pt.life <= 0 {
+			pt.active = false
+			continue
+		}
+		pt.vy += pt.gravity
+		pt.x += pt.vx
+		pt.y += pt.vy
+	}
+}
+
+// Draw renders every active particle, fading it out over its lifetime.
+func (pp *ParticlePool) Draw(screen *ebiten.Image, cam *Camera) {
+	for _, pt := range pp.items {
+		if !pt.active {
+			continue
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(particleSize, particleSize)
+		op.GeoM.Translate(pt.x-particleSize/2-cam.x, pt.y-particleSize/2-cam.y)
+		op.ColorScale.ScaleWithColor(pt.clr)
+		op.ColorScale.ScaleAlpha(float32(pt.life) / float32(pt.total))
+		screen.DrawImage(whitePixel(), op)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+// ResolveOrder picks which axis Move resolves first. Resolving one axis
+// before the other means a diagonal move into a corner can stop on either
+// face depending on the order, so the choice is visible in play.
+type ResolveOrder int
+
+const (
+	// ResolveXThenY moves horizontally, then vertically.
+	ResolveXThenY ResolveOrder = iota
+	// ResolveYThenX moves vertically, then horizontally.
+	ResolveYThenX
+	// ResolveLargerFirst resolves whichever axis has the faster velocity
+	// first, so corner behavior doesn't depend on the direction of travel.
+	ResolveLargerFirst
+)
+
+// PhysicsConfig holds tunable physics settings for a player. Speeds are in
+// pixels per reference tick, for a player one tile tall.
+type PhysicsConfig struct {
+	ResolveOrder ResolveOrder
+	Speed        float64 // walking and climbing speed
+	JumpSpeed    float64 // initial vertical velocity of a jump; negative is up
+	Gravity      float64 // added to vy every reference tick
+	MaxFall      float64 // terminal velocity
+}
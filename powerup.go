@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// PowerUpKind identifies what a power-up does.
+type PowerUpKind string
+
+const (
+	PowerUpSpeed      PowerUpKind = "speed"
+	PowerUpInvincible PowerUpKind = "invincible"
+	PowerUpHighJump   PowerUpKind = "highjump"
+)
+
+const (
+	powerUpDefaultSeconds = 8
+	speedBoostFactor      = 1.6
+	highJumpFactor        = 1.3
+)
+
+// powerUpSprites maps each kind to its (0-based) tilesheet index.
+var powerUpSprites = map[PowerUpKind]int{
+	PowerUpSpeed:      21,
+	PowerUpInvincible: 41,
+	PowerUpHighJump:   22,
+}
+
+// PowerUp is a collectible placed in the level.
+type PowerUp struct {
+	x, y      float64
+	kind      PowerUpKind
+	duration  int // ticks the effect lasts once collected
+	collected bool
+}
+
+// Effect is a timed modifier active on the player.
+type Effect struct {
+	kind      PowerUpKind
+	remaining int // ticks
+}
+
+// loadPowerUps creates a PowerUp for every object of type "powerup". The
+// object's "kind" property picks the effect and "duration" (seconds) how long
+// it lasts.
+func loadPowerUps(m *TiledMap) []PowerUp {
+	var powerUps []PowerUp
+	for _, o := range m.objectsOfType("powerup") {
+		kind := PowerUpKind(o.Properties.String("kind"))
+		if _, ok := powerUpSprites[kind]; !ok {
+			continue
+		}
+		seconds := o.Properties.Float("duration", powerUpDefaultSeconds)
+		powerUps = append(powerUps, PowerUp{
+			x:        o.X,
+			y:        o.Y,
+			kind:     kind,
+			duration: int(seconds * ebiten.DefaultTPS),
+		})
+	}
+	return powerUps
+}
+
+// addEffect applies a timed effect to the player. Collecting a kind that's
+// already active refreshes its timer instead of stacking a second copy.
+func (p *Player) addEffect(kind PowerUpKind, duration int) {
+	for i := range p.effects {
+		if p.effects[i].kind == kind {
+			p.effects[i].remaining = max(p.effects[i].remaining, duration)
+			return
+		}
+	}
+	p.effects = append(p.effects, Effect{kind: kind, remaining: duration})
+}
+
+// updateEffects counts down every active effect and drops expired ones.
+func (p *Player) updateEffects() {
+	active := p.effects[:0]
+	for _, e := range p.effects {
+		e.remaining--
+		if e.remaining > 0 {
+			active = append(active, e)
+		}
+	}
+	p.effects = active
+}
+
+// hasEffect reports whether the given effect is currently active.
+func (p *Player) hasEffect(kind PowerUpKind) bool {
+	for _, e := range p.effects {
+		if e.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// speedMultiplier and jumpMultiplier combine all active effects into a
+// single factor for Update to apply to its base constants.
+func (p *Player) speedMultiplier() float64 {
+	if p.hasEffect(PowerUpSpeed) {
+		return speedBoostFactor
+	}
+	return 1
+}
+
+func (p *Player) jumpMultiplier() float64 {
+	if p.hasEffect(PowerUpHighJump) {
+		return highJumpFactor
+	}
+	return 1
+}
+
+// collectPowerUps applies any power-up the player is touching.
+func collectPowerUps(powerUps []PowerUp, p *Player) {
+	ts := float64(p.tileSize) // power-ups are one tile
+	for i := range powerUps {
+		pu := &powerUps[i]
+		if pu.collected || !aabbOverlap(p.x, p.y, p.width, p.height, pu.x, pu.y, ts, ts) {
+			continue
+		}
+		pu.collected = true
+		p.addEffect(pu.kind, pu.duration)
+	}
+}
+
+// drawPowerUps renders the power-ups that haven't been collected yet.
+func drawPowerUps(screen, tiles *ebiten.Image, powerUps []PowerUp, cam *Camera) {
+	for _, pu := range powerUps {
+		if pu.collected {
+			continue
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(pu.x-cam.x, pu.y-cam.y)
+		screen.DrawImage(spriteAt(tiles, powerUpSprites[pu.kind], cam.tileSize), op)
+	}
+}
+
+// drawEffectTimers lists every player's active effects with their
+// remaining seconds in the top-right corner, below the HUD's timer. With
+// more than one player each line says whose effect it is.
+func drawEffectTimers(screen *ebiten.Image, players []Player) {
+	anchor := HUDAnchor{Anchor: TopRight, OffsetX: 2, OffsetY: hudLineHeight}
+	line := 0
+	for i := range players {
+		for _, e := range players[i].effects {
+			label := fmt.Sprintf("%s %d", e.kind, (e.remaining+ebiten.DefaultTPS-1)/ebiten.DefaultTPS)
+			if len(players) > 1 {
+				label = fmt.Sprintf("P%d %s", i+1, label)
+			}
+			drawHUDText(screen, label, anchor, line)
+			line++
+		}
+	}
+}
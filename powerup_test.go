@@ -0,0 +1,117 @@
+package main
+
+import "testing"
+
+func TestCollectPowerUp(t *testing.T) {
+	p := testPlayer(0, 0)
+	powerUps := []PowerUp{
+		{x: 8, y: 0, kind: PowerUpSpeed, duration: 10},
+		{x: 64, y: 0, kind: PowerUpHighJump, duration: 10},
+	}
+	collectPowerUps(powerUps, &p)
+	if !powerUps[0].collected || !p.hasEffect(PowerUpSpeed) {
+		t.Error("touching power-up not collected")
+	}
+	if powerUps[1].collected || p.hasEffect(PowerUpHighJump) {
+		t.Error("power-up out of reach collected")
+	}
+}
+
+func TestEffectTimer(t *testing.T) {
+	p := testPlayer(0, 0)
+	p.addEffect(PowerUpSpeed, 3)
+	p.addEffect(PowerUpSpeed, 5) // refreshes rather than stacking
+	p.addEffect(PowerUpSpeed, 2) // doesn't shorten it
+	if len(p.effects) != 1 || p.effects[0].remaining != 5 {
+		t.Fatalf("effects = %+v, want one speed effect with 5 ticks left", p.effects)
+	}
+	for i := range 5 {
+		if !p.hasEffect(PowerUpSpeed) {
+			t.Fatalf("effect ran out after %d ticks, want 5", i)
+		}
+		p.updateEffects()
+	}
+	if p.hasEffect(PowerUpSpeed) {
+		t.Error("effect still active after 5 ticks")
+	}
+}
+
+func TestInvincibleEffect(t *testing.T) {
+	p := testPlayer(0, 0)
+	p.addEffect(PowerUpInvincible, 10)
+	p.TakeDamage(1)
+	if p.health != playerMaxHealth {
+		t.Errorf("health = %d after damage while invincible, want %d", p.health, playerMaxHealth)
+	}
+}
+
+func TestSpeedAndJumpEffects(t *testing.T) {
+	run := func(p Player) float64 {
+		collision := testLayer(
+			"..........",
+			"..........",
+			"##########",
+		)
+		p.x, p.y = 0, 15
+		stepPlayer(&p, collision, nil, FrameInput{Right: true}, 30, nil)
+		return p.x
+	}
+	plain := testPlayer(0, 0)
+	fast := testPlayer(0, 0)
+	fast.addEffect(PowerUpSpeed, 1000)
+	if a, b := run(plain), run(fast); b <= a {
+		t.Errorf("ran %vpx with a speed boost and %vpx without; want further with it", b, a)
+	}
+
+	high := testPlayer(0, 0)
+	high.addEffect(PowerUpHighJump, 1000)
+	if a, b := jumpHeight(plain), jumpHeight(high); b <= a {
+		t.Errorf("jumped %vpx with high jump and %vpx without; want higher with it", b, a)
+	}
+
+	// Past the duration the player is back to normal.
+	expired := testPlayer(0, 0)
+	expired.addEffect(PowerUpSpeed, 10)
+	expired.addEffect(PowerUpHighJump, 10)
+	for range 11 {
+		expired.updateEffects()
+	}
+	if len(expired.effects) != 0 {
+		t.Errorf("effects = %+v after they ran out, want none", expired.effects)
+	}
+	if a, b := run(plain), run(expired); b != a {
+		t.Errorf("ran %vpx after the speed boost ran out and %vpx without one; want the same", b, a)
+	}
+	if a, b := jumpHeight(plain), jumpHeight(expired); b != a {
+		t.Errorf("jumped %vpx after the high jump ran out and %vpx without one; want the same", b, a)
+	}
+}
+
+// TestOverlappingEffects runs a short speed boost inside a longer high jump
+// and checks each lasts its own time.
+func TestOverlappingEffects(t *testing.T) {
+	p := testPlayer(0, 0)
+	p.addEffect(PowerUpHighJump, 10)
+	for range 3 {
+		p.updateEffects()
+	}
+	p.addEffect(PowerUpSpeed, 4)
+	for range 4 {
+		if !p.hasEffect(PowerUpSpeed) || !p.hasEffect(PowerUpHighJump) {
+			t.Fatalf("effects = %+v while both should be active", p.effects)
+		}
+		p.updateEffects()
+	}
+	if p.hasEffect(PowerUpSpeed) || !p.hasEffect(PowerUpHighJump) {
+		t.Errorf("effects = %+v after the speed boost ran out, want only the high jump", p.effects)
+	}
+	if p.speedMultiplier() != 1 || p.jumpMultiplier() != highJumpFactor {
+		t.Errorf("speed x%v and jump x%v, want x1 and x%v", p.speedMultiplier(), p.jumpMultiplier(), highJumpFactor)
+	}
+	for range 3 {
+		p.updateEffects()
+	}
+	if len(p.effects) != 0 {
+		t.Errorf("effects = %+v after the high jump ran out, want none", p.effects)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	projectileSize     = 4.0
+	projectileSpeed    = 1.2
+	projectileLifetime = 180 // ticks before an unblocked shot fizzles out
+	projectileDamage   = 1
+	shooterInterval    = 90 // ticks between shots
+	shooterRange       = 96.0
+	shooterSpriteIndex = 340
+)
+
+// Projectile is a single shot travelling in a straight line.
+type Projectile struct {
+	x, y   float64
+	vx, vy float64
+	life   float64 // remaining reference ticks
+	active bool
+}
+
+// ProjectilePool owns every projectile in the level. Spent projectiles are
+// marked inactive and their slots are reused, so steady firing doesn't
+// allocate.
+type ProjectilePool struct {
+	items []Projectile
+}
+
+// Spawn fires a projectile from (x, y) with the given velocity.
+func (pp *ProjectilePool) Spawn(x, y, vx, vy float64) {
+	p := Projectile{x: x, y: y, vx: vx, vy: vy, life: projectileLifetime, active: true}
+	for i := range pp.items {
+		if !pp.items[i].active {
+			pp.items[i] = p
+			return
+		}
+	}
+	pp.items = append(pp.items, p)
+}
+
+// solidAt reports whether the world point (px, py) is inside a solid tile
+// of a layer whose tiles are ts pixels on a side.
+func solidAt(collision *Layer, ts, px, py float64) bool {
+	return collision.solidAt(int(math.Floor(px/ts)), int(math.Floor(py/ts)))
+}
+
+// Update moves every active projectile, removing those that hit a solid
+// tile, hit a player, or run out of lifetime. The collision layer's tiles are
+// ts pixels on a side, and k is the length of the tick in reference ticks
+// (see tickScale). Projectiles pass through no-clip players.
+func (pp *ProjectilePool) Update(collision *Layer, players []Player, ts, k float64) {
+	for i := range pp.items {
+		pr := &pp.items[i]
+		if !pr.active {
+			continue
+		}
+		pr.x += pr.vx * k
+		pr.y += pr.vy * k
+		pr.life -= k
+
+		if pr.life <= 0 {
+			pr.active = false
+			continue
+		}
+		// Walls stop projectiles: test the tile under the projectile's center.
+		cx := pr.x + projectileSize/2
+		cy := pr.y + projectileSize/2
+		if solidAt(collision, ts, cx, cy) {
+			pr.active = false
+			continue
+		}
+		for i := range players {
+			player := &players[i]
+			if player.noClip {
+				continue
+			}
+			if aabbOverlap(pr.x, pr.y, projectileSize, projectileSize, player.x, player.y, player.width, player.height) {
+				player.TakeDamage(projectileDamage)
+				pr.active = false
+				break
+			}
+		}
+	}
+}
+
+// Draw renders every active projectile.
+func (pp *ProjectilePool) Draw(screen *ebiten.Image, cam *Camera) {
+	for _, pr := range pp.items {
+		if !pr.active {
+			continue
+		}
+		vector.DrawFilledRect(screen, float32(pr.x-cam.x), float32(pr.y-cam.y), projectileSize, projectileSize, color.White, false)
+	}
+}
+
+// Shooter is a stationary ranged enemy that fires at the player whenever
+// they're within range.
+type Shooter struct {
+	x, y     float64
+	cooldown float64 // reference ticks until the next shot
+}
+
+// loadShooters creates a Shooter for every object of type "shooter".
+func loadShooters(m *TiledMap) []Shooter {
+	var shooters []Shooter
+	for _, o := range m.objectsOfType("shooter") {
+		shooters = append(shooters, Shooter{x: o.X, y: o.Y, cooldown: shooterInterval})
+	}
+	return shooters
+}
+
+// Update counts down to the next shot and fires toward the center of the
+// nearest player, ignoring no-clip ones. Shooters are one tile, ts pixels,
+// on a side, and k is the length of the tick in reference ticks.
+func (s *Shooter) Update(players []Player, pool *ProjectilePool, ts, k float64) {
+	if s.cooldown > 0 {
+		s.cooldown -= k
+		return
+	}
+	sx, sy := s.x+ts/2, s.y+ts/2
+	dx, dy, dist := 0.0, 0.0, math.Inf(1)
+	for _, player := range players {
+		if player.noClip {
+			continue
+		}
+		px := player.x + player.width/2 - sx
+		py := player.y + player.height/2 - sy
+		if d := math.Hypot(px, py); d < dist {
+			dx, dy, dist = px, py, d
+		}
+	}
+	if dist == 0 || dist > shooterRange {
+		return
+	}
+	pool.Spawn(sx-projectileSize/2, sy-projectileSize/2, dx/dist*projectileSpeed, dy/dist*projectileSpeed)
+	s.cooldown = shooterInterval
+}
+
+// Draw renders the shooter from the tilesheet.
+func (s *Shooter) Draw(screen, tiles *ebiten.Image, cam *Camera) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(s.x-cam.x, s.y-cam.y)
+	screen.DrawImage(spriteAt(tiles, shooterSpriteIndex, cam.tileSize), op)
+}
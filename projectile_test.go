@@ -0,0 +1,108 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProjectileHitsPlayer(t *testing.T) {
+	tests := []struct {
+		name    string
+		noClip  bool
+		wantHit bool
+	}{
+		{"normal player", false, true},
+		{"no-clip player", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players := []Player{testPlayer(32, 0)}
+			players[0].noClip = tt.noClip
+			var pool ProjectilePool
+			pool.Spawn(20, 6, projectileSpeed, 0)
+			for range 20 {
+				pool.Update(nil, players, 16, 1)
+			}
+			hit := players[0].health < playerMaxHealth
+			if hit != tt.wantHit {
+				t.Errorf("player hit = %v, want %v", hit, tt.wantHit)
+			}
+			if pool.items[0].active == tt.wantHit {
+				t.Errorf("projectile still active = %v, want %v", pool.items[0].active, !tt.wantHit)
+			}
+		})
+	}
+}
+
+func TestProjectileStopsAtWall(t *testing.T) {
+	collision := testLayer("..#")
+	var pool ProjectilePool
+	pool.Spawn(0, 6, projectileSpeed, 0)
+	ticks := 0
+	for pool.items[0].active && ticks < projectileLifetime {
+		pool.Update(collision, nil, 16, 1)
+		ticks++
+	}
+	if pool.items[0].active {
+		t.Fatal("projectile flew through the wall")
+	}
+	if x := pool.items[0].x + projectileSize/2; x < 32 || x >= 32+projectileSpeed {
+		t.Errorf("projectile stopped with its center at x = %v, want just inside the wall at 32", x)
+	}
+}
+
+// TestProjectileTickScale checks that a projectile covers the same distance
+// and lives as long at any tick rate.
+func TestProjectileTickScale(t *testing.T) {
+	for _, k := range []float64{1, 0.5} {
+		var pool ProjectilePool
+		pool.Spawn(0, 0, projectileSpeed, 0)
+		steps := 0
+		for pool.items[0].active {
+			pool.Update(nil, nil, 16, k)
+			steps++
+		}
+		if want := int(projectileLifetime / k); steps != want {
+			t.Errorf("k = %v: projectile lived %d updates, want %d", k, steps, want)
+		}
+		if want := projectileSpeed * projectileLifetime; math.Abs(pool.items[0].x-want) > 1e-9 {
+			t.Errorf("k = %v: projectile fizzled at x = %v, want %v", k, pool.items[0].x, want)
+		}
+	}
+}
+
+func TestShooter(t *testing.T) {
+	tests := []struct {
+		name     string
+		x        float64
+		noClip   bool
+		k        float64
+		wantShot int // update the first shot is fired on, or 0 for none
+	}{
+		{"player in range", 48, false, 1, shooterInterval + 1},
+		{"half-length ticks", 48, false, 0.5, 2*shooterInterval + 1},
+		{"player out of range", 200, false, 1, 0},
+		{"no-clip player", 48, true, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players := []Player{testPlayer(tt.x, 0)}
+			players[0].noClip = tt.noClip
+			s := Shooter{x: 0, y: 0, cooldown: shooterInterval}
+			var pool ProjectilePool
+			shot := 0
+			for i := 1; i <= 3*shooterInterval && shot == 0; i++ {
+				s.Update(players, &pool, 16, tt.k)
+				if len(pool.items) > 0 {
+					shot = i
+				}
+			}
+			if shot != tt.wantShot {
+				t.Fatalf("first shot on update %d, want %d", shot, tt.wantShot)
+			}
+			if shot != 0 && pool.items[0].vx <= 0 {
+				t.Errorf("shot fired with vx = %v, want it aimed right at the player", pool.items[0].vx)
+			}
+		})
+	}
+}
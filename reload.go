@@ -0,0 +1,78 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// reloadInterval is how many ticks pass between checks of the level file.
+const reloadInterval = ebiten.DefaultTPS
+
+// levelWatch tracks the on-disk file of the current level so it can be
+// reloaded when it's saved.
+type levelWatch struct {
+	path    string
+	modTime time.Time
+	timer   int
+}
+
+// checkReload reloads the current level once a second if its file in
+// g.assetDir has changed since it was loaded. Only levels loaded from disk
+// are watched. A map that fails to decode is logged and the previous one
+// kept, so a half-saved file doesn't end the session.
+func (g *Game) checkReload() {
+	if g.assetDir == "" {
+		return
+	}
+	g.watch.timer++
+	if g.watch.timer < reloadInterval {
+		return
+	}
+	g.watch.timer = 0
+
+	path := filepath.Join(g.assetDir, g.level+".json")
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if path != g.watch.path {
+		// A new level since the last check: start watching it from here.
+		g.watch.path, g.watch.modTime = path, info.ModTime()
+		return
+	}
+	if !info.ModTime().After(g.watch.modTime) {
+		return
+	}
+	g.watch.modTime = info.ModTime()
+
+	m, err := g.readLevel(g.level)
+	if err != nil {
+		log.Printf("reload: %v; keeping the previous map", err)
+		return
+	}
+	g.setLevel(g.level, m)
+	g.keepPlayerInLevel()
+	log.Printf("reloaded %s", path)
+}
+
+// keepPlayerInLevel leaves the players where they were after a reload if
+// that's still inside the map and out of the walls, nudging them free if
+// they're only just stuck. If anyone is left outside, everyone goes back
+// to the PlayerStart.
+func (g *Game) keepPlayerInLevel() {
+	collision := g.tilemap.collisionLayer()
+	mapW, mapH := g.mapSize()
+	for i := range g.players {
+		p := &g.players[i]
+		p.ejectFromSolids(collision)
+		inside := p.x >= 0 && p.y >= 0 && p.x+p.width <= mapW && p.y+p.height <= mapH
+		if !inside || (collision != nil && p.collides(p.x, p.y, collision)) {
+			g.spawnPlayer()
+			return
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeLevel saves m as the named level in dir, stamped with modTime.
+func writeLevel(t *testing.T, dir, name string, m TiledMap, modTime time.Time) {
+	t.Helper()
+	path := filepath.Join(dir, name+".json")
+	if err := SaveMap(m, path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// reloadLevelWith watches the test level from a file in a temporary
+// directory, then saves edited over it and runs checkReload until it's
+// picked up.
+func reloadLevelWith(t *testing.T, g *Game, edited TiledMap) {
+	t.Helper()
+	g.assetDir = t.TempDir()
+	g.level = "test"
+	start := time.Now().Add(-time.Hour)
+	writeLevel(t, g.assetDir, g.level, g.tilemap, start)
+	for range reloadInterval {
+		g.checkReload()
+	}
+	if g.watch.path == "" {
+		t.Fatal("level file not being watched")
+	}
+	writeLevel(t, g.assetDir, g.level, edited, start.Add(time.Minute))
+	for range reloadInterval {
+		g.checkReload()
+	}
+}
+
+func TestReloadKeepsMomentum(t *testing.T) {
+	g := testLevel()
+	p := &g.players[0]
+	p.x, p.y, p.vx, p.vy = 80, 64, 1.5, -2
+
+	var edited TiledMap
+	if err := json.Unmarshal([]byte(`{"width": 20, "height": 10, "properties": [{"name": "name", "value": "Edited"}],
+	 "layers": [{"name": "Objects", "type": "objectgroup", "objects": [{"name": "PlayerStart", "x": 16, "y": 128, "width": 16, "height": 16}]}]}`), &edited); err != nil {
+		t.Fatal(err)
+	}
+	reloadLevelWith(t, g, edited)
+	if g.tilemap.StringProperty("name") != "Edited" {
+		t.Fatal("edited level not reloaded")
+	}
+	if p.x != 80 || p.y != 64 || p.vx != 1.5 || p.vy != -2 {
+		t.Errorf("after the reload: at (%v, %v) moving (%v, %v); want still at (80, 64) moving (1.5, -2)", p.x, p.y, p.vx, p.vy)
+	}
+}
+
+func TestReloadRespawnsPlayerInWall(t *testing.T) {
+	g := testLevel()
+	p := &g.players[0]
+	p.x, p.y, p.vx = 96, 64, 1.5
+
+	edited := g.tilemap
+	collision := Layer{Name: "Collision", Type: "tilelayer", Width: 20, Height: 10, Data: make([]int, 200)}
+	// Fill columns 2 to 13 top to bottom, too deep for the player to be
+	// nudged free.
+	for ty := range 10 {
+		for tx := 2; tx <= 13; tx++ {
+			collision.Data[ty*20+tx] = 1
+		}
+	}
+	edited.Layers = append([]Layer{collision}, edited.Layers...)
+	reloadLevelWith(t, g, edited)
+	if p.x != 16 || p.y != 128 || p.vx != 0 {
+		t.Errorf("player at (%v, %v) moving %v after a wall was added on them, want back at the start (16, 128) and stopped", p.x, p.y, p.vx)
+	}
+}
+
+// The reloadLevel tests run against the shipped "tilemap" level, whose second
+// row is a solid platform from x = 16 to x = 144.
+
+func TestReloadLevelKeepsMomentum(t *testing.T) {
+	g := &Game{cfg: DefaultConfig(), players: []Player{testPlayer(48, 32)}}
+	if err := g.loadLevel("tilemap"); err != nil {
+		t.Fatal(err)
+	}
+	p := &g.players[0]
+	p.vx, p.vy = 1.5, -2
+	kept, err := g.reloadLevel(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !kept || p.x != 48 || p.y != 32 || p.vx != 1.5 || p.vy != -2 {
+		t.Errorf("after the reload: kept %v, at (%v, %v) moving (%v, %v); want kept at (48, 32) moving (1.5, -2)", kept, p.x, p.y, p.vx, p.vy)
+	}
+
+	kept, err = g.reloadLevel(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept || p.x != 48 || p.y != 32 || p.vx != 0 || p.vy != 0 {
+		t.Errorf("reload without momentum: kept %v, at (%v, %v) moving (%v, %v); want in place and stopped", kept, p.x, p.y, p.vx, p.vy)
+	}
+}
+
+func TestReloadLevelMovesPlayerOutOfWall(t *testing.T) {
+	g := &Game{cfg: DefaultConfig(), players: []Player{testPlayer(32, 16)}}
+	if err := g.loadLevel("tilemap"); err != nil {
+		t.Fatal(err)
+	}
+	p := &g.players[0]
+	p.vx = 1.5
+	kept, err := g.reloadLevel(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept || p.vx != 0 {
+		t.Errorf("reload with the player inside the platform: kept %v, moving %v; want stopped", kept, p.vx)
+	}
+	if p.collides(p.x, p.y, g.tilemap.collisionLayer()) {
+		t.Errorf("player still inside a solid tile at (%v, %v)", p.x, p.y)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import "math"
+
+// Rope tunables, in pixels per reference tick.
+const (
+	ropeClimbFactor  = 0.6  // climbing speed as a fraction of walking speed
+	ropeSwingAccel   = 0.15 // swing speed gained per tick while holding Left or Right
+	ropeSwingMax     = 3.0  // fastest the player can swing
+	ropeSwingDamping = 0.05 // fraction of the swing lost per tick without input
+	ropeSwingPull    = 0.02 // swing speed the rope takes back per pixel the player hangs off center
+	ropeSwingReach   = 0.75 // furthest the player swings out from the rope, in tiles
+	ropeReleaseTicks = 12   // ticks after letting go before the player can steer or grab again
+)
+
+// getRopeLayer returns the layer named "Ropes".
+func getRopeLayer(layers []Layer) *Layer {
+	return getLayerByName(layers, "Ropes")
+}
+
+// checkRope reports whether the player's center is close enough to a rope
+// tile they overlap to hold on, with the same leeway as a ladder, and the
+// column of that rope.
+func (p *Player) checkRope(rope *Layer) (int, bool) {
+	if rope == nil {
+		return 0, false
+	}
+	ts := float64(p.tileSize)
+	centerX := p.x + p.width/2
+	threshold := p.ladderCenterThreshold()
+	left, right := tileSpan(p.x, p.width, ts)
+	top, bottom := tileSpan(p.y, p.height, ts)
+	for ty := top; ty <= bottom; ty++ {
+		for tx := left; tx <= right; tx++ {
+			if rope.tileAt(tx, ty) != 0 && math.Abs(centerX-(float64(tx)+0.5)*ts) <= threshold {
+				return tx, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// ropeAnchor returns the x the player hangs at, straight down from the rope
+// column they hold.
+func (p *Player) ropeAnchor() float64 {
+	return (float64(p.ropeColumn)+0.5)*float64(p.tileSize) - p.width/2
+}
+
+// holdingRope reports whether the rope column the player holds still has a
+// tile level with them, however far they've swung from it.
+func (p *Player) holdingRope(rope *Layer) bool {
+	top, bottom := tileSpan(p.y, p.height, float64(p.tileSize))
+	for ty := top; ty <= bottom; ty++ {
+		if rope.tileAt(p.ropeColumn, ty) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// updateRope handles the player while they hang on a rope, grabbing one
+// when they press Up against it. Left and Right swing the player out from
+// the rope like a pendulum instead of walking, and jumping lets go with the
+// swing carried into the jump. It reports whether it moved the player this
+// tick, in which case the rest of Update is skipped.
+func (p *Player) updateRope(rope, collision, breakable *Layer, blocks []Block, in FrameInput, speed, jumpSpeed, k float64) bool {
+	if !p.onRope {
+		tx, ok := p.checkRope(rope)
+		if !ok || !in.Up || p.knockbackTimer > 0 || p.dashTimer > 0 {
+			return false
+		}
+		p.onRope = true
+		p.onLadder, p.onGround, p.isJumping = false, false, false
+		p.ropeColumn = tx
+		p.ropeOffset = p.x - p.ropeAnchor()
+		p.ropeSwing = max(min(p.vx, ropeSwingMax), -ropeSwingMax)
+		debugf("updateRope - Grabbed rope at column %d", tx)
+	}
+	if !p.holdingRope(rope) {
+		// Climbed off either end.
+		p.onRope = false
+		return false
+	}
+
+	if in.JumpPressed {
+		p.onRope = false
+		p.vx, p.vy = p.ropeSwing, jumpSpeed
+		if p.ropeSwing != 0 {
+			p.facingLeft = p.ropeSwing < 0
+		}
+		// Lock steering briefly, as after a wall jump, so the swing carries.
+		p.knockbackTimer = ropeReleaseTicks
+		p.isJumping, p.jumped = true, true
+		p.jumpBuffer = 0
+		p.Move(collision, nil, nil, breakable, blocks, k)
+		debugf("updateRope - Let go with swing %.2f", p.ropeSwing)
+		return true
+	}
+
+	switch {
+	case in.Left:
+		p.ropeSwing = max(p.ropeSwing-ropeSwingAccel*k, -ropeSwingMax)
+		p.facingLeft = true
+	case in.Right:
+		p.ropeSwing = min(p.ropeSwing+ropeSwingAccel*k, ropeSwingMax)
+		p.facingLeft = false
+	default:
+		p.ropeSwing *= math.Pow(1-ropeSwingDamping, k)
+	}
+	// The rope pulls the player back under it, so they swing to and fro.
+	p.ropeSwing -= p.ropeOffset * ropeSwingPull * k
+	reach := ropeSwingReach * float64(p.tileSize)
+	p.ropeOffset += p.ropeSwing * k
+	if math.Abs(p.ropeOffset) > reach {
+		p.ropeOffset = math.Copysign(reach, p.ropeOffset)
+		p.ropeSwing = 0
+	}
+
+	// Move to the end of the swing so walls and blocks stop it.
+	target := p.ropeAnchor() + p.ropeOffset
+	p.vx, p.vy = (target-p.x)/k, 0
+	if in.Up {
+		p.vy = -speed * ropeClimbFactor
+	} else if in.Down {
+		p.vy = speed * ropeClimbFactor
+	}
+	p.Move(collision, nil, nil, breakable, blocks, k)
+	p.vx = 0
+	if math.Abs(p.x-target) > 0.01 {
+		// Swung into something.
+		p.ropeOffset = p.x - p.ropeAnchor()
+		p.ropeSwing = 0
+	}
+	if p.onGround {
+		// Climbed down to the floor.
+		p.onRope = false
+		p.ropeSwing = 0
+	}
+	return true
+}
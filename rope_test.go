@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+// TestRopeSwings grabs a rope and checks holding Right, then Left, swings
+// the player out either side of it while they keep hold.
+func TestRopeSwings(t *testing.T) {
+	rope := testLayer(
+		"..#...",
+		"..#...",
+		"..#...",
+		"..#...",
+		"..#...",
+		"......",
+		"......",
+		"......",
+	)
+	collision := testLayer(
+		"......",
+		"......",
+		"......",
+		"......",
+		"......",
+		"......",
+		"......",
+		"######",
+	)
+	p := testPlayer(32, 32)
+	step := func(in FrameInput, ticks int) (minX, maxX float64) {
+		minX, maxX = p.x, p.x
+		for range ticks {
+			p.Update(collision, nil, nil, nil, rope, nil, nil, in, 1.0/referenceTPS)
+			if !p.onRope {
+				t.Fatalf("let go of the rope at (%v, %v)", p.x, p.y)
+			}
+			minX, maxX = min(minX, p.x), max(maxX, p.x)
+		}
+		return minX, maxX
+	}
+	step(FrameInput{Up: true}, 1)
+	if _, maxX := step(FrameInput{Right: true}, 30); maxX < 36 {
+		t.Errorf("holding Right swung the player no further right than x = %v, want past 36", maxX)
+	}
+	if minX, _ := step(FrameInput{Left: true}, 60); minX > 28 {
+		t.Errorf("holding Left swung the player no further left than x = %v, want past 28", minX)
+	}
+	if reach := ropeSwingReach * 16; p.x < 32-reach || p.x > 32+reach {
+		t.Errorf("player at x = %v, more than %v from the rope at 32", p.x, reach)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Rumble tunables. Magnitudes run from 0 to 1.
+const (
+	rumbleLandSpeed     = 5.0 // landing speed, in pixels per tick, that counts as a hard fall
+	rumbleLandDuration  = 80 * time.Millisecond
+	rumbleLandMagnitude = 0.3
+	rumbleHurtDuration  = 250 * time.Millisecond
+	rumbleHurtMagnitude = 0.8
+)
+
+// Rumble vibrates the gamepad driving the player, if it's a standard
+// gamepad. Ebiten ignores the request on gamepads that can't vibrate, and
+// with no gamepad connected nothing happens.
+func (in *InputState) Rumble(d time.Duration, magnitude float64) {
+	if in.Pad >= len(in.gamepads) || !ebiten.IsStandardGamepadLayoutAvailable(in.gamepads[in.Pad]) {
+		return
+	}
+	ebiten.VibrateGamepad(in.gamepads[in.Pad], &ebiten.VibrateGamepadOptions{
+		Duration:        d,
+		StrongMagnitude: magnitude,
+		WeakMagnitude:   magnitude,
+	})
+}
+
+// rumble gives each player haptic feedback for their hard landing or
+// damage this tick.
+func (g *Game) rumble() {
+	for i := range g.players {
+		p, in := &g.players[i], &g.inputs[i]
+		switch {
+		case p.hurt:
+			in.Rumble(rumbleHurtDuration, rumbleHurtMagnitude)
+		case p.landed && p.landSpeed >= rumbleLandSpeed:
+			in.Rumble(rumbleLandDuration, rumbleLandMagnitude)
+		}
+	}
+}
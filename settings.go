@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Settings are the player's preferences, kept between runs.
+type Settings struct {
+	Fullscreen bool `json:"fullscreen"`
+}
+
+// settingsPath returns where the settings file lives in the user's config
+// directory.
+func settingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ebiten_platformer", "settings.json"), nil
+}
+
+// loadSettings reads the saved settings. A missing or unreadable file gives
+// the defaults, so a bad file never stops the game from starting.
+func loadSettings() Settings {
+	var s Settings
+	path, err := settingsPath()
+	if err != nil {
+		log.Printf("settings: %v", err)
+		return s
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("settings: %v", err)
+		}
+		return s
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		log.Printf("settings: ignoring %s: %v", path, err)
+		return Settings{}
+	}
+	return s
+}
+
+// saveSettings writes s to the settings file, creating its directory if
+// needed.
+func saveSettings(s Settings) error {
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", " ")
+	if err != nil {
+		return fmt.Errorf("encoding settings: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating settings directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing settings %s: %w", path, err)
+	}
+	return nil
+}
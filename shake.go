@@ -0,0 +1,52 @@
+package main
+
+import "math/rand/v2"
+
+// Screen shake tunables. Magnitudes are the largest offset in pixels.
+const (
+	shakeLandSpeed    = 5.0 // landing speed, in pixels per tick, that shakes the screen
+	shakeLandPerSpeed = 0.8 // magnitude per pixel per tick of landing speed past shakeLandSpeed
+	shakeLandMax      = 3.0
+	shakeLandTicks    = 10
+	shakeHurtMag      = 3.0
+	shakeHurtTicks    = 18
+)
+
+// Shake starts a screen shake of the given magnitude lasting ticks,
+// unless a stronger one is already running.
+func (c *Camera) Shake(magnitude float64, ticks int) {
+	if magnitude*float64(ticks) < c.shakeMagnitude*float64(c.shakeTimer) {
+		return
+	}
+	c.shakeMagnitude, c.shakeTimer, c.shakeTicks = magnitude, ticks, ticks
+}
+
+// updateShake counts the shake down by one tick.
+func (c *Camera) updateShake() {
+	if c.shakeTimer > 0 {
+		c.shakeTimer--
+	}
+}
+
+// shakeOffset returns a random offset for this frame, shrinking as the
+// shake runs out. It's zero when the camera isn't shaking.
+func (c *Camera) shakeOffset() (float64, float64) {
+	if c.shakeTimer <= 0 {
+		return 0, 0
+	}
+	m := c.shakeMagnitude * float64(c.shakeTimer) / float64(c.shakeTicks)
+	return (rand.Float64()*2 - 1) * m, (rand.Float64()*2 - 1) * m
+}
+
+// shake starts a screen shake for any player's hard landing or damage this
+// tick; the harder the landing, the bigger the shake.
+func (g *Game) shake() {
+	for _, p := range g.players {
+		switch {
+		case p.hurt:
+			g.camera.Shake(shakeHurtMag, shakeHurtTicks)
+		case p.landed && p.landSpeed >= shakeLandSpeed:
+			g.camera.Shake(min((p.landSpeed-shakeLandSpeed+1)*shakeLandPerSpeed, shakeLandMax), shakeLandTicks)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+const sampleRate = 44100
+
+// SoundEffect names a one-shot sound.
+type SoundEffect int
+
+const (
+	SoundJump SoundEffect = iota
+	SoundLand
+	SoundCoin
+)
+
+// defaultMusic is the track for levels without a "music" property.
+const defaultMusic = "overworld"
+
+// musicTracks are the notes of each track a level's "music" property can
+// name, looped as an arpeggio by musicLoop.
+var musicTracks = map[string][]float64{
+	"overworld": {262, 330, 392, 330, 220, 262, 330, 262, 175, 220, 262, 220, 196, 247, 294, 247},
+	"cave":      {220, 262, 330, 262, 165, 196, 247, 196, 175, 208, 262, 208, 165, 208, 247, 208},
+}
+
+// Sound plays the background music and sound effects. Everything is
+// synthesized at startup, so no audio files need embedding.
+type Sound struct {
+	ctx   *audio.Context
+	sfx   map[SoundEffect][]byte
+	music *audio.Player
+	track string // the name of the track music is playing
+	muted bool
+}
+
+// NewSound creates the audio context. The music starts with the first
+// PlayMusic.
+func NewSound() *Sound {
+	return &Sound{
+		ctx: audio.NewContext(sampleRate),
+		sfx: map[SoundEffect][]byte{
+			SoundJump: sweepTone(330, 660, 0.12, 0.25),
+			SoundLand: sweepTone(140, 90, 0.06, 0.3),
+			SoundCoin: append(sweepTone(988, 988, 0.05, 0.2), sweepTone(1319, 1319, 0.12, 0.2)...),
+		},
+	}
+}
+
+// musicTrack returns the track to play for a level's "music" property:
+// name itself if there's a track by that name, or defaultMusic.
+func musicTrack(name string) string {
+	if _, ok := musicTracks[name]; ok {
+		return name
+	}
+	if name != "" {
+		log.Printf("music: no track %q, playing %q", name, defaultMusic)
+	}
+	return defaultMusic
+}
+
+// PlayMusic switches the looping music to the track musicTrack picks for
+// name. The track already playing carries on rather than restarting.
+func (s *Sound) PlayMusic(name string) {
+	if s == nil {
+		return
+	}
+	track := musicTrack(name)
+	if s.music != nil && s.track == track {
+		return
+	}
+	song := musicLoop(musicTracks[track])
+	loop := audio.NewInfiniteLoop(bytes.NewReader(song), int64(len(song)))
+	music, err := s.ctx.NewPlayer(loop)
+	if err != nil {
+		log.Printf("music: %v", err)
+		return
+	}
+	if s.music != nil {
+		s.music.Close()
+	}
+	if s.muted {
+		music.SetVolume(0)
+	} else {
+		music.SetVolume(0.4)
+	}
+	music.Play()
+	s.music, s.track = music, track
+}
+
+// Play starts a sound effect unless the game is muted.
+func (s *Sound) Play(e SoundEffect) {
+	if s == nil || s.muted {
+		return
+	}
+	s.ctx.NewPlayerFromBytes(s.sfx[e]).Play()
+}
+
+// ToggleMute silences or restores the music and sound effects.
+func (s *Sound) ToggleMute() {
+	if s == nil {
+		return
+	}
+	s.muted = !s.muted
+	if s.music == nil {
+		return
+	}
+	if s.muted {
+		s.music.SetVolume(0)
+	} else {
+		s.music.SetVolume(0.4)
+	}
+}
+
+// sweepTone returns a square wave gliding from freq to endFreq over seconds,
+// fading out, as 16-bit stereo PCM.
+func sweepTone(freq, endFreq, seconds, volume float64) []byte {
+	n := int(seconds * sampleRate)
+	buf := make([]byte, n*4)
+	phase := 0.0
+	for i := range n {
+		t := float64(i) / float64(n)
+		phase += (freq + (endFreq-freq)*t) / sampleRate
+		v := volume * (1 - t)
+		if math.Mod(phase, 1) >= 0.5 {
+			v = -v
+		}
+		sample := uint16(int16(v * math.MaxInt16))
+		binary.LittleEndian.PutUint16(buf[i*4:], sample)
+		binary.LittleEndian.PutUint16(buf[i*4+2:], sample)
+	}
+	return buf
+}
+
+// musicLoop plays notes as a short arpeggio that loops seamlessly.
+func musicLoop(notes []float64) []byte {
+	var song []byte
+	for _, f := range notes {
+		song = append(song, sweepTone(f, f, 0.25, 0.12)...)
+	}
+	return song
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// GameState is which screen the game is on.
+type GameState int
+
+const (
+	StateMenu GameState = iota
+	StatePlaying
+	StatePaused
+	StateGameOver
+)
+
+// defaultMenuTitle is shown on the menu when the first level has no "name"
+// property.
+const defaultMenuTitle = "EBITEN PLATFORMER"
+
+// startPressed reports whether player one asked to start from a menu
+// screen: jump, or Enter.
+func (g *Game) startPressed() bool {
+	return g.inputs[0].Frame.JumpPressed || inpututil.IsKeyJustPressed(ebiten.KeyEnter)
+}
+
+// startGame begins a fresh run from the first level: score, lives, the
+// clock, and player one, with all their timers and effects, start over as
+// they were in NewGame. Anyone else has to join again.
+func (g *Game) startGame() {
+	g.score = 0
+	g.lives = startingLives
+	g.ticks = 0
+	p := newPlayer(g.cfg)
+	p.physics = g.players[0].physics // keep any live tuning
+	g.players = []Player{p}
+	g.inputDelays = newInputDelays(len(g.inputs), g.cfg.InputDelay)
+	if err := g.startLevel(g.firstLevel); err != nil {
+		log.Printf("start: %v", err)
+	}
+	g.state = StatePlaying
+}
+
+// drawMenu dims the first level behind the title and a start prompt for
+// the device the player last used.
+func (g *Game) drawMenu(screen *ebiten.Image) {
+	title := g.tilemap.StringProperty("name")
+	if title == "" {
+		title = defaultMenuTitle
+	}
+	dimScreen(screen)
+	drawHUDText(screen, title, HUDAnchor{Anchor: Center}, -1)
+	drawHUDText(screen, "Press "+g.inputs[0].Prompt(ActionJump)+" to Start", HUDAnchor{Anchor: Center}, 1)
+}
+
+// drawGameOver freezes the last frame behind the final score and a prompt
+// to play again.
+func (g *Game) drawGameOver(screen *ebiten.Image) {
+	dimScreen(screen)
+	drawHUDText(screen, "GAME OVER", HUDAnchor{Anchor: Center}, -1)
+	drawHUDText(screen, fmt.Sprintf("SCORE %d", g.score), HUDAnchor{Anchor: Center}, 0)
+	drawHUDText(screen, "Press "+g.inputs[0].Prompt(ActionJump)+" to Restart", HUDAnchor{Anchor: Center}, 1)
+}
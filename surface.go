@@ -0,0 +1,90 @@
+package main
+
+import "math"
+
+// Surface holds the physics coefficients of a ground tile.
+type Surface struct {
+	// Friction is how much of the player's horizontal speed is lost each
+	// tick while they're not pressing a direction: 1 stops them dead, small
+	// values slide.
+	Friction float64
+	// Bounce is the fraction of landing speed sent back upward; 0 means the
+	// player just lands.
+	Bounce float64
+	// Impulse is added to the player's horizontal position every tick they
+	// stand on the tile, like a conveyor belt.
+	Impulse float64
+	// Spring launches the player at springSpeed whenever they land on the
+	// tile, however fast they came down.
+	Spring bool
+}
+
+// defaultSurface is ordinary ground.
+var defaultSurface = Surface{Friction: 1}
+
+// iceFriction is the friction of tiles marked "ice": the player keeps 92% of
+// their speed each tick and slides to a stop.
+const iceFriction = 0.08
+
+// minBounceSpeed is the slowest upward speed a bouncy tile will launch the
+// player at; anything less and they just land, so bounces die out.
+const minBounceSpeed = 1.0
+
+// springSpeed is the upward velocity a spring tile gives the player; a
+// normal jump is -5.
+const springSpeed = -8.0
+
+// buildTileSurfaces reads the "friction", "bounce", "impulse", and "spring"
+// tile properties from every tileset in m. A true "ice" property is
+// shorthand for iceFriction; an explicit "friction" still wins.
+func buildTileSurfaces(m *TiledMap) map[int]Surface {
+	surfaces := map[int]Surface{}
+	for _, ts := range m.Tilesets {
+		for _, td := range ts.tileDefs() {
+			friction := defaultSurface.Friction
+			if td.Properties.Bool("ice") {
+				friction = iceFriction
+			}
+			s := Surface{
+				Friction: td.Properties.Float("friction", friction),
+				Bounce:   td.Properties.Float("bounce", defaultSurface.Bounce),
+				Impulse:  td.Properties.Float("impulse", defaultSurface.Impulse),
+				Spring:   td.Properties.Bool("spring"),
+			}
+			if s != defaultSurface {
+				surfaces[ts.FirstGID+td.ID] = s
+			}
+		}
+	}
+	return surfaces
+}
+
+// surface returns the coefficients for a GID: its own if the level's
+// tilesets give it custom physics, otherwise defaultSurface.
+func (t *levelTiles) surface(gid int) Surface {
+	if t == nil {
+		return defaultSurface
+	}
+	if s, ok := t.surfaces[gid]; ok {
+		return s
+	}
+	return defaultSurface
+}
+
+// groundTile returns the GID of the solid tile in the row containing feetY
+// (the bottom edge of the player's box), preferring the one under their
+// center, or 0 if there is none.
+func (p *Player) groundTile(collision *Layer, feetY float64) int {
+	ty := int(math.Floor(feetY / float64(p.tileSize)))
+	center := int(math.Floor((p.x + p.width/2) / float64(p.tileSize)))
+	if gid := collision.tileAt(center, ty); gid != 0 {
+		return gid
+	}
+	left, right := tileSpan(p.x, p.width, float64(p.tileSize))
+	for tx := left; tx <= right; tx++ {
+		if gid := collision.tileAt(tx, ty); gid != 0 {
+			return gid
+		}
+	}
+	return 0
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildTileSurfaces(t *testing.T) {
+	src := `{"tilesets": [{"firstgid": 1, "name": "terrain", "tiles": [
+	 {"id": 0, "properties": [{"name": "ice", "type": "bool", "value": true}]},
+	 {"id": 1, "properties": [{"name": "ice", "type": "bool", "value": true}, {"name": "friction", "type": "float", "value": 0.5}]},
+	 {"id": 2, "properties": [{"name": "bounce", "type": "float", "value": 0.7}]},
+	 {"id": 3, "properties": [{"name": "impulse", "type": "float", "value": -0.5}, {"name": "spring", "type": "bool", "value": true}]},
+	 {"id": 4, "properties": [{"name": "color", "type": "string", "value": "red"}]}
+	]}]}`
+	var m TiledMap
+	if err := json.Unmarshal([]byte(src), &m); err != nil {
+		t.Fatal(err)
+	}
+	surfaces := buildTileSurfaces(&m)
+	want := map[int]Surface{
+		1: {Friction: iceFriction},
+		2: {Friction: 0.5},
+		3: {Friction: 1, Bounce: 0.7},
+		4: {Friction: 1, Impulse: -0.5, Spring: true},
+	}
+	if len(surfaces) != len(want) {
+		t.Errorf("got surfaces for %d tiles, want %d: %v", len(surfaces), len(want), surfaces)
+	}
+	for gid, w := range want {
+		if got := surfaces[gid]; got != w {
+			t.Errorf("surface of GID %d = %+v, want %+v", gid, got, w)
+		}
+	}
+	tiles := &levelTiles{surfaces: surfaces}
+	if got := tiles.surface(5); got != defaultSurface {
+		t.Errorf("surface of a tile with no physics properties = %+v, want the default", got)
+	}
+}
+
+func TestParseTSXSurfaces(t *testing.T) {
+	tsx := `<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" name="terrain" tilewidth="16" tileheight="16" columns="4">
+ <image source="terrain.png" width="64" height="64"/>
+ <tile id="2">
+  <properties>
+   <property name="bounce" type="float" value="0.25"/>
+   <property name="spring" type="bool" value="true"/>
+   <property name="label" value="pad"/>
+  </properties>
+ </tile>
+ <tile id="5">
+  <animation>
+   <frame tileid="5" duration="100"/>
+   <frame tileid="6" duration="150"/>
+  </animation>
+ </tile>
+</tileset>`
+	ext, err := parseTSX([]byte(tsx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ext.name != "terrain" || ext.image != "terrain.png" || ext.columns != 4 {
+		t.Errorf("tileset %q, image %q, %d columns; want terrain, terrain.png, 4", ext.name, ext.image, ext.columns)
+	}
+	m := TiledMap{Tilesets: []Tileset{{FirstGID: 10, Source: "terrain.tsx", ext: ext}}}
+	if got, want := buildTileSurfaces(&m)[12], (Surface{Friction: 1, Bounce: 0.25, Spring: true}); got != want {
+		t.Errorf("surface of GID 12 = %+v, want %+v", got, want)
+	}
+	if got := ext.tiles[0].Properties.String("label"); got != "pad" {
+		t.Errorf("untyped property = %q, want %q", got, "pad")
+	}
+	if frames := ext.tiles[1].Animation; len(frames) != 2 || frames[1] != (AnimationFrame{TileID: 6, Duration: 150}) {
+		t.Errorf("animation = %+v, want two frames ending on tile 6 for 150ms", frames)
+	}
+
+	if _, err := parseTSX([]byte(`<tileset><tile id="0"><properties><property name="bounce" type="float" value="high"/></properties></tile></tileset>`)); err == nil {
+		t.Error("parseTSX accepted a float property that isn't a number")
+	}
+}
+
+// TestEmbeddedTSX loads a tileset the shipped levels reference from the
+// embedded copy.
+func TestEmbeddedTSX(t *testing.T) {
+	g := &Game{}
+	g.tilemap.Tilesets = []Tileset{{FirstGID: 1, Source: "../maps_tiled/monochrome_tilemap_transparent_packed.tsx"}}
+	g.loadExternalTilesets()
+	ext := g.tilemap.Tilesets[0].ext
+	if ext == nil {
+		t.Fatal("embedded .tsx not loaded")
+	}
+	if ext.image == "" || ext.columns == 0 {
+		t.Errorf("embedded tileset has image %q and %d columns, want both set", ext.image, ext.columns)
+	}
+}
+
+// surfaceFloor returns a floor of GID 1 tiles with surface s.
+func surfaceFloor(s Surface) *Layer {
+	l := testLayer(
+		"..........",
+		"..........",
+		"..........",
+		"..........",
+		"##########",
+	)
+	l.tiles = &levelTiles{surfaces: map[int]Surface{1: s}}
+	return l
+}
+
+func TestIceSlides(t *testing.T) {
+	slide := func(s Surface) float64 {
+		collision := surfaceFloor(s)
+		p := testPlayer(0, 47)
+		stepPlayer(&p, collision, nil, FrameInput{}, 10, nil)
+		stepPlayer(&p, collision, nil, FrameInput{Right: true}, 20, nil)
+		x := p.x
+		stepPlayer(&p, collision, nil, FrameInput{}, 60, nil)
+		return p.x - x
+	}
+	ground, ice := slide(defaultSurface), slide(Surface{Friction: iceFriction})
+	if ice <= ground {
+		t.Errorf("slid %vpx on ice and %vpx on ground after letting go; want further on ice", ice, ground)
+	}
+}
+
+func TestBounceAndSpring(t *testing.T) {
+	// peak drops the player from 16px above the floor and returns how high
+	// their feet get above the floor after the first landing.
+	peak := func(s Surface) float64 {
+		collision := surfaceFloor(s)
+		p := testPlayer(0, 31)
+		landed := false
+		var floor, apex float64
+		stepPlayer(&p, collision, nil, FrameInput{}, 120, func(int) {
+			if !landed && (p.onGround || p.vy < 0) {
+				landed = true
+				floor, apex = p.y+p.height, p.y+p.height
+			}
+			apex = min(apex, p.y+p.height)
+		})
+		return floor - apex
+	}
+	if h := peak(defaultSurface); h != 0 {
+		t.Errorf("ordinary ground bounced the player %vpx", h)
+	}
+	bounce := peak(Surface{Friction: 1, Bounce: 0.9})
+	if bounce <= 0 || bounce >= 16 {
+		t.Errorf("bouncy ground sent the player %vpx up after a 16px drop, want some but less", bounce)
+	}
+	if spring := peak(Surface{Friction: 1, Spring: true}); spring <= 16 {
+		t.Errorf("spring sent the player %vpx up after a 16px drop, want higher", spring)
+	}
+}
+
+func TestConveyor(t *testing.T) {
+	collision := surfaceFloor(Surface{Friction: 1, Impulse: 0.5})
+	p := testPlayer(0, 47)
+	stepPlayer(&p, collision, nil, FrameInput{}, 10, nil)
+	x := p.x
+	stepPlayer(&p, collision, nil, FrameInput{}, 10, nil)
+	if got := p.x - x; got != 5 {
+		t.Errorf("conveyor moved the player %vpx in 10 ticks, want 5", got)
+	}
+}
+
+// TestLandOnBlockWithoutCollision lands on a Block in a map with no
+// Collision layer, which used to look up the surface on a nil layer.
+func TestLandOnBlockWithoutCollision(t *testing.T) {
+	p := testPlayer(0, 0)
+	p.vy = 8
+	p.Move(nil, nil, nil, nil, []Block{{x: 0, y: 20}}, 1)
+	if !p.onGround || p.y != 4 {
+		t.Errorf("player at y = %v, onGround %v; want standing on the block at 4", p.y, p.onGround)
+	}
+	if p.surface != defaultSurface {
+		t.Errorf("surface on a block = %+v, want %+v", p.surface, defaultSurface)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+// teleportCooldownTicks is how long the player has to be off every
+// teleport pad before one will fire again, so arriving on a pad doesn't
+// send them straight back.
+const teleportCooldownTicks = 20
+
+// teleportPartner returns the other "teleport" object sharing o's "id"
+// property.
+func (g *Game) teleportPartner(o Object) (Object, bool) {
+	id := o.Properties.String("id")
+	for _, t := range g.tilemap.objectsOfType("teleport") {
+		if t.Properties.String("id") == id && (t.X != o.X || t.Y != o.Y) {
+			return t, true
+		}
+	}
+	return Object{}, false
+}
+
+// checkTeleports moves each player to the partner of any teleport pad they
+// step on. Velocity is zeroed unless the pad's "keepvelocity" property is
+// true. The camera snaps to a destination that's off screen and eases to
+// one that's already in view.
+func (g *Game) checkTeleports() {
+	for i := range g.players {
+		g.teleport(&g.players[i])
+	}
+}
+
+// teleport does the work of checkTeleports for one player.
+func (g *Game) teleport(p *Player) {
+	var pad Object
+	onPad := false
+	for _, o := range g.tilemap.objectsOfType("teleport") {
+		if aabbOverlap(p.x, p.y, p.width, p.height, o.X, o.Y, max(o.Width, 1), max(o.Height, 1)) {
+			pad, onPad = o, true
+			break
+		}
+	}
+	if !onPad {
+		p.teleportTimer = max(p.teleportTimer-1, 0)
+		return
+	}
+	if p.teleportTimer > 0 {
+		return
+	}
+	dest, ok := g.teleportPartner(pad)
+	if !ok {
+		return
+	}
+	vx, vy := p.vx, p.vy
+	p.placeAt(dest)
+	p.onGround = false
+	if pad.Properties.Bool("keepvelocity") {
+		p.vx, p.vy = vx, vy
+	}
+	p.teleportTimer = teleportCooldownTicks
+	debugf("checkTeleports - Teleported to %q (id %q)", dest.Name, pad.Properties.String("id"))
+
+	vw, vh := g.camera.view()
+	if !aabbOverlap(p.x, p.y, p.width, p.height, g.camera.x, g.camera.y, vw, vh) {
+		mapW, mapH := g.mapSize()
+		g.camera.Snap(g.players, mapW, mapH)
+	}
+}
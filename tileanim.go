@@ -0,0 +1,65 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// tileAnimation is a tile's animation with its frames resolved to GIDs.
+type tileAnimation struct {
+	gids      []int
+	durations []int // milliseconds
+	total     int
+}
+
+// tileAnimations collects the animation of every tile, across the map's
+// tilesets, that has one.
+func (m *TiledMap) tileAnimations() map[int]tileAnimation {
+	anims := map[int]tileAnimation{}
+	for _, ts := range m.Tilesets {
+		for _, td := range ts.tileDefs() {
+			var a tileAnimation
+			for _, f := range td.Animation {
+				if f.Duration <= 0 {
+					continue
+				}
+				a.gids = append(a.gids, ts.FirstGID+f.TileID)
+				a.durations = append(a.durations, f.Duration)
+				a.total += f.Duration
+			}
+			if a.total > 0 {
+				anims[ts.FirstGID+td.ID] = a
+			}
+		}
+	}
+	return anims
+}
+
+// animated reports whether gid has an animation in the level.
+func (t *levelTiles) animated(gid int) bool {
+	_, ok := t.animation(gid)
+	return ok
+}
+
+// animation returns gid's animation, if it has one.
+func (t *levelTiles) animation(gid int) (tileAnimation, bool) {
+	if t == nil {
+		return tileAnimation{}, false
+	}
+	a, ok := t.animations[gid]
+	return a, ok
+}
+
+// animatedGID returns the GID to show for gid after ticks ticks: the
+// current frame of its animation, or gid itself if it isn't animated.
+func (t *levelTiles) animatedGID(gid, ticks int) int {
+	a, ok := t.animation(gid)
+	if !ok {
+		return gid
+	}
+	ms := (ticks * 1000 / ebiten.DefaultTPS) % a.total
+	for i, d := range a.durations {
+		if ms < d {
+			return a.gids[i]
+		}
+		ms -= d
+	}
+	return gid
+}
@@ -0,0 +1,627 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// Tiled stores flipped and rotated tiles by setting the top bits of the GID.
+const (
+	flipHorizontal = 0x80000000
+	flipVertical   = 0x40000000
+	flipDiagonal   = 0x20000000
+	gidMask        = 0x1fffffff
+)
+
+// tileGID strips the flip flags from a raw layer value, leaving the GID.
+func tileGID(raw int) int {
+	return raw & gidMask
+}
+
+// TiledMap represents the JSON map exported from Tiled.
+type TiledMap struct {
+	Height     int        `json:"height"`
+	Width      int        `json:"width"`
+	Tilewidth  int        `json:"tilewidth"`
+	Tileheight int        `json:"tileheight"`
+	Layers     []Layer    `json:"layers"`
+	Tilesets   []Tileset  `json:"tilesets"`
+	Properties Properties `json:"properties,omitempty"`
+
+	// Infinite maps store tile layers as chunks. They're stitched into
+	// ordinary layers on load, so this is only true while decoding.
+	Infinite bool `json:"infinite"`
+
+	// originX and originY are the Tiled tile coordinates of tile (0, 0)
+	// after an infinite map is stitched; zero for ordinary maps.
+	originX, originY int
+
+	// derivedCollision is built from the collision tilesets when the map has
+	// no Collision layer. It isn't part of the saved map.
+	derivedCollision *Layer
+
+	// tiles is what the tilesets say about individual tiles, built by
+	// attachTiles when the map becomes a level.
+	tiles *levelTiles
+
+	// raw keeps every field from the source JSON, including the ones the
+	// loader doesn't use, so SaveMap can write them back untouched.
+	raw map[string]json.RawMessage
+}
+
+// Layer represents a layer in the Tiled JSON. Tile layers fill Data; object
+// layers ("objectgroup") fill Objects.
+type Layer struct {
+	Name    string   `json:"name"`
+	Data    []int    `json:"data,omitempty"`
+	Objects []Object `json:"objects,omitempty"`
+	Width   int      `json:"width"`
+	Height  int      `json:"height"`
+	Type    string   `json:"type"`
+	Opacity float64  `json:"opacity"` // 0 is transparent, 1 opaque
+	Visible bool     `json:"visible"`
+
+	chunks []layerChunk // an infinite map's tile data, until it's stitched
+
+	// tiles is the per-tile data of the map the layer belongs to, shared by
+	// all its layers. It's nil until the map is loaded as a level.
+	tiles *levelTiles
+
+	Properties Properties `json:"properties,omitempty"`
+
+	raw map[string]json.RawMessage
+}
+
+// Object is a shape placed on a Tiled object layer. Positions are in pixels.
+type Object struct {
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	X          float64    `json:"x"`
+	Y          float64    `json:"y"`
+	Width      float64    `json:"width"`
+	Height     float64    `json:"height"`
+	Properties Properties `json:"properties,omitempty"`
+
+	raw map[string]json.RawMessage
+}
+
+// Property is one entry of a Tiled "properties" array. Value holds whatever
+// JSON type Tiled wrote for Type: numbers decode as float64, "bool" as bool,
+// and everything else (string, color, file) as string.
+type Property struct {
+	Name  string `json:"name"`
+	Type  string `json:"type,omitempty"`
+	Value any    `json:"value"`
+}
+
+// Properties is a Tiled custom property list.
+type Properties []Property
+
+// Get returns the raw value of the named property.
+func (ps Properties) Get(name string) (any, bool) {
+	for _, p := range ps {
+		if p.Name == name {
+			return p.Value, true
+		}
+	}
+	return nil, false
+}
+
+// String returns the named property as a string, or "" if it's missing or
+// not a string.
+func (ps Properties) String(name string) string {
+	v, _ := ps.Get(name)
+	s, _ := v.(string)
+	return s
+}
+
+// Float returns the named property as a float64, or fallback if it's
+// missing or not a number.
+func (ps Properties) Float(name string, fallback float64) float64 {
+	v, _ := ps.Get(name)
+	if f, ok := v.(float64); ok {
+		return f
+	}
+	return fallback
+}
+
+// Int returns the named property as an int, or fallback if it's missing or
+// not a number.
+func (ps Properties) Int(name string, fallback int) int {
+	v, _ := ps.Get(name)
+	if f, ok := v.(float64); ok {
+		return int(f)
+	}
+	return fallback
+}
+
+// Bool returns the named property as a bool, or false if it's missing or
+// not a bool.
+func (ps Properties) Bool(name string) bool {
+	v, _ := ps.Get(name)
+	b, _ := v.(bool)
+	return b
+}
+
+// StringProperty returns a map-level string property such as "name",
+// "music", or "next".
+func (m *TiledMap) StringProperty(name string) string {
+	return m.Properties.String(name)
+}
+
+// FloatProperty returns a map-level numeric property such as "par_time".
+func (m *TiledMap) FloatProperty(name string, fallback float64) float64 {
+	return m.Properties.Float(name, fallback)
+}
+
+// Tileset is a tileset reference in the Tiled JSON. External tilesets only
+// carry FirstGID and Source, and the rest is read from the .tsx; embedded
+// ones also name their Image and list per-tile data in Tiles.
+type Tileset struct {
+	FirstGID int       `json:"firstgid"`
+	Source   string    `json:"source,omitempty"`
+	Name     string    `json:"name,omitempty"`
+	Image    string    `json:"image,omitempty"`
+	Columns  int       `json:"columns,omitempty"`
+	Tiles    []TileDef `json:"tiles,omitempty"`
+
+	// ext is the parsed .tsx of an external tileset; see
+	// loadExternalTilesets.
+	ext *externalTileset
+
+	raw map[string]json.RawMessage
+}
+
+// TileDef is per-tile data in a tileset. ID is local to the tileset, so
+// its GID is FirstGID + ID.
+type TileDef struct {
+	ID         int              `json:"id"`
+	Properties Properties       `json:"properties,omitempty"`
+	Animation  []AnimationFrame `json:"animation,omitempty"`
+
+	raw map[string]json.RawMessage
+}
+
+// AnimationFrame is one frame of a tile animation: the local ID of the tile
+// to show and for how many milliseconds.
+type AnimationFrame struct {
+	TileID   int `json:"tileid"`
+	Duration int `json:"duration"`
+}
+
+// key returns the name Config.CollisionTilesets matches against.
+func (ts *Tileset) key() string {
+	if ts.Name != "" {
+		return ts.Name
+	}
+	if ts.ext != nil && ts.ext.name != "" {
+		return ts.ext.name
+	}
+	base := path.Base(ts.Source)
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+func (m *TiledMap) UnmarshalJSON(data []byte) error {
+	type plain TiledMap // plain has no methods, so this doesn't recurse
+	if err := json.Unmarshal(data, (*plain)(m)); err != nil {
+		return err
+	}
+	if m.Infinite {
+		m.stitchChunks()
+	}
+	return json.Unmarshal(data, &m.raw)
+}
+
+func (m TiledMap) MarshalJSON() ([]byte, error) {
+	type plain TiledMap
+	known, err := json.Marshal(plain(m))
+	if err != nil {
+		return nil, err
+	}
+	return mergeRawJSON(m.raw, known)
+}
+
+// UnmarshalJSON accepts tile data either as a plain array of GIDs or as a
+// base64 string, optionally zlib- or gzip-compressed, as Tiled writes it
+// depending on the map's layer format setting. Infinite maps' chunks are
+// decoded the same way.
+func (l *Layer) UnmarshalJSON(data []byte) error {
+	type plain Layer
+	aux := struct {
+		*plain
+		Data        json.RawMessage `json:"data"`
+		Encoding    string          `json:"encoding"`
+		Compression string          `json:"compression"`
+		Chunks      []struct {
+			X, Y, Width, Height int
+			Data                json.RawMessage
+		} `json:"chunks"`
+	}{plain: (*plain)(l)}
+	// Tiled leaves these out when they're at their defaults.
+	l.Opacity, l.Visible = 1, true
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	gids, err := decodeLayerData(aux.Data, aux.Encoding, aux.Compression)
+	if err != nil {
+		return fmt.Errorf("layer %q: %w", l.Name, err)
+	}
+	l.Data = gids
+	for _, c := range aux.Chunks {
+		gids, err := decodeLayerData(c.Data, aux.Encoding, aux.Compression)
+		if err != nil {
+			return fmt.Errorf("layer %q chunk (%d, %d): %w", l.Name, c.X, c.Y, err)
+		}
+		l.chunks = append(l.chunks, layerChunk{X: c.X, Y: c.Y, Width: c.Width, Height: c.Height, Data: gids})
+	}
+	return json.Unmarshal(data, &l.raw)
+}
+
+// staleLayerKeys are source fields that stop describing a layer once it's
+// loaded: its data is rewritten as a plain array, and chunks are stitched.
+var staleLayerKeys = map[string]bool{
+	"encoding":    true,
+	"compression": true,
+	"chunks":      true,
+	"startx":      true,
+	"starty":      true,
+}
+
+// MarshalJSON always writes Data as a plain array, so any encoding or
+// chunking the layer was read with is dropped rather than left describing
+// data it no longer matches.
+func (l Layer) MarshalJSON() ([]byte, error) {
+	type plain Layer
+	known, err := json.Marshal(plain(l))
+	if err != nil {
+		return nil, err
+	}
+	raw := make(map[string]json.RawMessage, len(l.raw))
+	for k, v := range l.raw {
+		if !staleLayerKeys[k] {
+			raw[k] = v
+		}
+	}
+	return mergeRawJSON(raw, known)
+}
+
+// decodeLayerData turns a tile layer's "data" field into GIDs. Flip flags
+// are kept, since they live in the same 32-bit values.
+func decodeLayerData(data json.RawMessage, encoding, compression string) ([]int, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	switch encoding {
+	case "", "csv":
+		var gids []int
+		if err := json.Unmarshal(data, &gids); err != nil {
+			return nil, err
+		}
+		return gids, nil
+	case "base64":
+	default:
+		return nil, fmt.Errorf("unsupported layer encoding %q", encoding)
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("decoding base64 layer data: %w", err)
+	}
+
+	var r io.ReadCloser
+	switch compression {
+	case "":
+	case "zlib":
+		r, err = zlib.NewReader(bytes.NewReader(b))
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(b))
+	default:
+		return nil, fmt.Errorf("unsupported layer compression %q", compression)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decompressing %s layer data: %w", compression, err)
+	}
+	if r != nil {
+		defer r.Close()
+		if b, err = io.ReadAll(r); err != nil {
+			return nil, fmt.Errorf("decompressing %s layer data: %w", compression, err)
+		}
+	}
+
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("layer data is %d bytes, not a whole number of tiles", len(b))
+	}
+	gids := make([]int, len(b)/4)
+	for i := range gids {
+		gids[i] = int(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+	return gids, nil
+}
+
+func (o *Object) UnmarshalJSON(data []byte) error {
+	type plain Object
+	if err := json.Unmarshal(data, (*plain)(o)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &o.raw)
+}
+
+func (o Object) MarshalJSON() ([]byte, error) {
+	type plain Object
+	known, err := json.Marshal(plain(o))
+	if err != nil {
+		return nil, err
+	}
+	return mergeRawJSON(o.raw, known)
+}
+
+func (ts *Tileset) UnmarshalJSON(data []byte) error {
+	type plain Tileset
+	if err := json.Unmarshal(data, (*plain)(ts)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &ts.raw)
+}
+
+func (ts Tileset) MarshalJSON() ([]byte, error) {
+	type plain Tileset
+	known, err := json.Marshal(plain(ts))
+	if err != nil {
+		return nil, err
+	}
+	return mergeRawJSON(ts.raw, known)
+}
+
+func (td *TileDef) UnmarshalJSON(data []byte) error {
+	type plain TileDef
+	if err := json.Unmarshal(data, (*plain)(td)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &td.raw)
+}
+
+func (td TileDef) MarshalJSON() ([]byte, error) {
+	type plain TileDef
+	known, err := json.Marshal(plain(td))
+	if err != nil {
+		return nil, err
+	}
+	return mergeRawJSON(td.raw, known)
+}
+
+// mergeRawJSON overlays the fields in known (a marshaled JSON object) on top
+// of the preserved raw fields, so edited values win and unknown ones survive.
+func mergeRawJSON(raw map[string]json.RawMessage, known []byte) ([]byte, error) {
+	fields := make(map[string]json.RawMessage, len(raw))
+	for k, v := range raw {
+		fields[k] = v
+	}
+	var overlay map[string]json.RawMessage
+	if err := json.Unmarshal(known, &overlay); err != nil {
+		return nil, err
+	}
+	for k, v := range overlay {
+		fields[k] = v
+	}
+	return json.Marshal(fields)
+}
+
+// SaveMap writes m back out as Tiled JSON, including any runtime edits to its
+// layers. Fields the loader doesn't model are written back as they were read.
+func SaveMap(m TiledMap, path string) error {
+	data, err := json.MarshalIndent(m, "", " ")
+	if err != nil {
+		return fmt.Errorf("encoding map: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing map %s: %w", path, err)
+	}
+	return nil
+}
+
+// tileAt returns the GID at tile coordinate (tx, ty), without flip flags,
+// or 0 when the coordinate is outside the layer.
+func (l *Layer) tileAt(tx, ty int) int {
+	if l == nil || tx < 0 || ty < 0 || tx >= l.Width || ty >= l.Height {
+		return 0
+	}
+	i := ty*l.Width + tx
+	if i >= len(l.Data) {
+		return 0
+	}
+	return tileGID(l.Data[i])
+}
+
+// tilesetFor returns the tileset a GID belongs to: the one with the highest
+// FirstGID that is still <= gid. It returns nil for empty tiles.
+func (m *TiledMap) tilesetFor(gid int) *Tileset {
+	gid = tileGID(gid)
+	if gid == 0 {
+		return nil
+	}
+	var found *Tileset
+	for i := range m.Tilesets {
+		ts := &m.Tilesets[i]
+		if ts.FirstGID <= gid && (found == nil || ts.FirstGID > found.FirstGID) {
+			found = ts
+		}
+	}
+	return found
+}
+
+// isCollisionGID reports whether gid comes from one of the level's
+// collision tilesets.
+func (m *TiledMap) isCollisionGID(gid int) bool {
+	ts := m.tilesetFor(gid)
+	return ts != nil && m.tiles != nil && m.tiles.solidTilesets[ts.key()]
+}
+
+// collisionLayer returns the layer collision checks run against: the layer
+// named "Collision" if there is one, otherwise a layer merged from every tile
+// layer's collision-tileset tiles. The merged layer is also built, empty,
+// when the level only has blocked cells such as doors. It returns nil if
+// none of that applies.
+func (m *TiledMap) collisionLayer() *Layer {
+	if l := getCollisionLayer(m.Layers); l != nil {
+		return l
+	}
+	if m.tiles == nil || len(m.tiles.solidTilesets) == 0 && len(m.tiles.blocked) == 0 {
+		return nil
+	}
+	if m.derivedCollision == nil {
+		solid := Layer{Name: "Collision", Type: "tilelayer", Width: m.Width, Height: m.Height, Data: make([]int, m.Width*m.Height)}
+		for _, l := range m.Layers {
+			if l.Type != "tilelayer" {
+				continue
+			}
+			for i, gid := range l.Data {
+				if i < len(solid.Data) && m.isCollisionGID(gid) {
+					solid.Data[i] = gid
+				}
+			}
+		}
+		solid.tiles = m.tiles
+		m.derivedCollision = &solid
+	}
+	return m.derivedCollision
+}
+
+// objectsOfType returns every object across the map's object layers whose
+// Type matches typ.
+func (m *TiledMap) objectsOfType(typ string) []Object {
+	var found []Object
+	for _, l := range m.Layers {
+		for _, o := range l.Objects {
+			if o.Type == typ {
+				found = append(found, o)
+			}
+		}
+	}
+	return found
+}
+
+// objectNamed returns the first object across the map's object layers with
+// the given name.
+func (m *TiledMap) objectNamed(name string) (Object, bool) {
+	for _, l := range m.Layers {
+		for _, o := range l.Objects {
+			if o.Name == name {
+				return o, true
+			}
+		}
+	}
+	return Object{}, false
+}
+
+// tilesWithProperty returns the GIDs of every tile, across the map's
+// tilesets, whose bool property name is true.
+func (m *TiledMap) tilesWithProperty(name string) map[int]bool {
+	gids := map[int]bool{}
+	for _, ts := range m.Tilesets {
+		for _, td := range ts.tileDefs() {
+			if td.Properties.Bool(name) {
+				gids[ts.FirstGID+td.ID] = true
+			}
+		}
+	}
+	return gids
+}
+
+// tileProperties returns the custom properties of the tile with the given
+// GID, or nil if it has none.
+func (m *TiledMap) tileProperties(gid int) Properties {
+	gid = tileGID(gid)
+	ts := m.tilesetFor(gid)
+	if ts == nil {
+		return nil
+	}
+	for _, td := range ts.tileDefs() {
+		if ts.FirstGID+td.ID == gid {
+			return td.Properties
+		}
+	}
+	return nil
+}
+
+// levelTiles is what a level's tilesets say about individual tiles, by GID.
+// Each loaded level has its own, so nothing leaks from one level to the next.
+type levelTiles struct {
+	surfaces   map[int]Surface       // tiles with custom physics; see buildTileSurfaces
+	animations map[int]tileAnimation // animated tiles
+	oneWay     map[int]bool          // collision tiles the player can jump up through
+
+	// solidTilesets are the keys of the tilesets whose every tile is solid,
+	// from Config.CollisionTilesets.
+	solidTilesets map[string]bool
+
+	// blocked holds tile cells that are solid whatever the Collision layer
+	// has there, such as locked doors. They're kept out of the layer data so
+	// they never end up in a saved map.
+	blocked map[[2]int]bool
+}
+
+// attachTiles builds m's per-tile data from its tilesets and shares it with
+// every layer. solidTilesets names the tilesets collisionLayer treats as
+// solid when m has no Collision layer.
+func (m *TiledMap) attachTiles(solidTilesets []string) {
+	t := &levelTiles{
+		surfaces:   buildTileSurfaces(m),
+		animations: m.tileAnimations(),
+		oneWay:     m.tilesWithProperty("oneway"),
+	}
+	t.solidTilesets = map[string]bool{}
+	for _, name := range solidTilesets {
+		t.solidTilesets[name] = true
+	}
+	m.tiles = t
+	for i := range m.Layers {
+		m.Layers[i].tiles = t
+	}
+	m.derivedCollision = nil
+}
+
+// setBlocked makes cell (tx, ty) solid or clears it.
+func (t *levelTiles) setBlocked(tx, ty int, solid bool) {
+	if t == nil {
+		return
+	}
+	if !solid {
+		delete(t.blocked, [2]int{tx, ty})
+		return
+	}
+	if t.blocked == nil {
+		t.blocked = map[[2]int]bool{}
+	}
+	t.blocked[[2]int{tx, ty}] = true
+}
+
+// isBlocked reports whether cell (tx, ty) has been made solid by setBlocked.
+func (t *levelTiles) isBlocked(tx, ty int) bool {
+	return t != nil && t.blocked[[2]int{tx, ty}]
+}
+
+// solidAt reports whether cell (tx, ty) of a collision layer is solid: it
+// has a tile, or it's blocked.
+func (l *Layer) solidAt(tx, ty int) bool {
+	return l != nil && (l.tileAt(tx, ty) != 0 || l.tiles.isBlocked(tx, ty))
+}
+
+// isOneWay reports whether gid is a collision tile the player can jump up
+// through, from the "oneway" tile property. Every other collision tile is a
+// solid wall, floor, and ceiling.
+func (t *levelTiles) isOneWay(gid int) bool {
+	return t != nil && t.oneWay[gid]
+}
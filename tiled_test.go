@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// sampleMapJSON is a small map with fields the loader doesn't model, which
+// SaveMap should write back as they were.
+const sampleMapJSON = `{
+ "width": 3, "height": 2, "tilewidth": 16, "tileheight": 16,
+ "orientation": "orthogonal", "tiledversion": "1.10.2", "nextlayerid": 3,
+ "properties": [{"name": "music", "type": "string", "value": "cave"}],
+ "layers": [
+  {"id": 1, "name": "Collision", "type": "tilelayer", "width": 3, "height": 2,
+   "x": 0, "y": 0, "data": [0, 0, 0, 1, 1, 1]},
+  {"id": 2, "name": "Objects", "type": "objectgroup", "draworder": "topdown",
+   "objects": [{"id": 7, "name": "PlayerStart", "rotation": 0, "x": 16, "y": 0,
+    "width": 16, "height": 16}]}
+ ],
+ "tilesets": [{"firstgid": 1, "name": "tiles", "image": "tiles.png", "columns": 4,
+  "tilecount": 16, "tiles": [{"id": 0, "probability": 0.5,
+   "properties": [{"name": "solid", "type": "bool", "value": true}]}]}]
+}`
+
+func TestSaveMapRoundTrip(t *testing.T) {
+	var m TiledMap
+	if err := json.Unmarshal([]byte(sampleMapJSON), &m); err != nil {
+		t.Fatal(err)
+	}
+	m.Layers[0].Data[1] = 1 // an editor change
+	path := filepath.Join(t.TempDir(), "map.json")
+	if err := SaveMap(m, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got TiledMap
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("reloading the saved map: %v", err)
+	}
+
+	if !reflect.DeepEqual(got.Layers[0].Data, m.Layers[0].Data) {
+		t.Errorf("collision data = %v, want the edited %v", got.Layers[0].Data, m.Layers[0].Data)
+	}
+	if got.Width != 3 || got.Height != 2 || got.Tilewidth != 16 {
+		t.Errorf("map size = %dx%d at %dpx, want 3x2 at 16px", got.Width, got.Height, got.Tilewidth)
+	}
+	if o := got.Layers[1].Objects; len(o) != 1 || o[0].Name != "PlayerStart" || o[0].X != 16 {
+		t.Errorf("objects = %+v, want the PlayerStart at x = 16", o)
+	}
+	if got.StringProperty("music") != "cave" {
+		t.Errorf("music property = %q, want %q", got.StringProperty("music"), "cave")
+	}
+	if !got.Tilesets[0].Tiles[0].Properties.Bool("solid") {
+		t.Error("tile property solid lost on save")
+	}
+
+	// The fields the loader ignores come back unchanged, at every level.
+	unknown := []struct {
+		name string
+		raw  map[string]json.RawMessage
+		key  string
+		want string
+	}{
+		{"map", got.raw, "tiledversion", `"1.10.2"`},
+		{"map", got.raw, "nextlayerid", `3`},
+		{"layer", got.Layers[0].raw, "id", `1`},
+		{"object layer", got.Layers[1].raw, "draworder", `"topdown"`},
+		{"object", got.Layers[1].Objects[0].raw, "id", `7`},
+		{"tileset", got.Tilesets[0].raw, "tilecount", `16`},
+		{"tile", got.Tilesets[0].Tiles[0].raw, "probability", `0.5`},
+	}
+	for _, u := range unknown {
+		if v := string(u.raw[u.key]); v != u.want {
+			t.Errorf("%s field %q = %s after saving, want %s", u.name, u.key, v, u.want)
+		}
+	}
+}
+
+func TestSaveMapDropsLayerEncoding(t *testing.T) {
+	// A single GID 1, little-endian and base64-encoded.
+	src := `{"width": 1, "height": 1, "layers": [{"name": "Collision", "type": "tilelayer",
+	 "width": 1, "height": 1, "encoding": "base64", "data": "AQAAAA=="}]}`
+	var m TiledMap
+	if err := json.Unmarshal([]byte(src), &m); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got TiledMap
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("reloading the saved map: %v", err)
+	}
+	if _, ok := got.Layers[0].raw["encoding"]; ok {
+		t.Error("saved layer still claims to be base64-encoded")
+	}
+	if !reflect.DeepEqual(got.Layers[0].Data, []int{1}) {
+		t.Errorf("data = %v, want [1]", got.Layers[0].Data)
+	}
+}
+
+// tilesetMap returns a 3x1 map whose Ground layer holds a "terrain" tile,
+// a tile from an external "decor" tileset, and a flipped "terrain" tile.
+func tilesetMap() TiledMap {
+	return TiledMap{
+		Width: 3, Height: 1, Tilewidth: 16, Tileheight: 16,
+		Tilesets: []Tileset{
+			{FirstGID: 1, Name: "terrain"},
+			{FirstGID: 10, Source: "../tilesets/decor.tsx"},
+		},
+		Layers: []Layer{
+			{Name: "Ground", Type: "tilelayer", Width: 3, Height: 1, Data: []int{2, 11, 3 | flipHorizontal}},
+			{Name: "Objects", Type: "objectgroup"},
+		},
+	}
+}
+
+func TestDerivedCollisionLayer(t *testing.T) {
+	tests := []struct {
+		name  string
+		solid []string
+		want  []int // nil for no collision layer
+	}{
+		{"no collision tilesets", nil, nil},
+		{"terrain", []string{"terrain"}, []int{2, 0, 3 | flipHorizontal}},
+		{"external tileset by file name", []string{"decor"}, []int{0, 11, 0}},
+		{"both", []string{"terrain", "decor"}, []int{2, 11, 3 | flipHorizontal}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tilesetMap()
+			m.attachTiles(tt.solid)
+			l := m.collisionLayer()
+			if tt.want == nil {
+				if l != nil {
+					t.Fatalf("collision layer = %v, want none", l.Data)
+				}
+				return
+			}
+			if l == nil {
+				t.Fatal("no collision layer derived")
+			}
+			if !reflect.DeepEqual(l.Data, tt.want) {
+				t.Errorf("collision data = %v, want %v", l.Data, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollisionLayerPrefersNamedLayer(t *testing.T) {
+	m := tilesetMap()
+	m.Layers = append(m.Layers, Layer{Name: "Collision", Type: "tilelayer", Width: 3, Height: 1, Data: []int{0, 0, 1}})
+	m.attachTiles([]string{"terrain"})
+	if l := m.collisionLayer(); l != &m.Layers[2] {
+		t.Errorf("collision layer = %+v, want the map's own Collision layer", l)
+	}
+}
+
+func TestBlockedCells(t *testing.T) {
+	m := tilesetMap()
+	m.attachTiles(nil)
+	m.tiles.setBlocked(1, 0, true)
+	l := m.collisionLayer()
+	if l == nil {
+		t.Fatal("no collision layer for a map with a blocked cell")
+	}
+	p := testPlayer(16, 0)
+	if !p.collides(16, 0, l) {
+		t.Error("blocked cell doesn't collide")
+	}
+	m.tiles.setBlocked(1, 0, false)
+	if p.collides(16, 0, l) {
+		t.Error("cleared cell still collides")
+	}
+}
+
+func TestMapProperties(t *testing.T) {
+	src := `{"width": 1, "height": 1, "properties": [
+	 {"name": "name", "type": "string", "value": "Caves"},
+	 {"name": "time", "type": "int", "value": 90},
+	 {"name": "lightradius", "type": "float", "value": 40.5},
+	 {"name": "dark", "type": "bool", "value": true},
+	 {"name": "ambient", "type": "color", "value": "#ff202040"},
+	 {"name": "music", "type": "file", "value": "caves.ogg"}
+	]}`
+	var m TiledMap
+	if err := json.Unmarshal([]byte(src), &m); err != nil {
+		t.Fatal(err)
+	}
+	strs := []struct{ name, want string }{
+		{"name", "Caves"},
+		{"ambient", "#ff202040"},
+		{"music", "caves.ogg"},
+		{"time", ""}, // not a string
+		{"missing", ""},
+	}
+	for _, s := range strs {
+		if got := m.StringProperty(s.name); got != s.want {
+			t.Errorf("StringProperty(%q) = %q, want %q", s.name, got, s.want)
+		}
+	}
+	nums := []struct {
+		name string
+		want float64
+	}{
+		{"time", 90},
+		{"lightradius", 40.5},
+		{"name", -1}, // not a number, so the fallback
+		{"missing", -1},
+	}
+	for _, n := range nums {
+		if got := m.FloatProperty(n.name, -1); got != n.want {
+			t.Errorf("FloatProperty(%q) = %v, want %v", n.name, got, n.want)
+		}
+	}
+	if got := m.Properties.Int("time", 0); got != 90 {
+		t.Errorf("Int(%q) = %d, want 90", "time", got)
+	}
+	if !m.Properties.Bool("dark") || m.Properties.Bool("name") || m.Properties.Bool("missing") {
+		t.Error("Bool reads something other than a true bool property as true")
+	}
+}
+
+// encodeGIDs packs gids the way Tiled does for base64 layers, compressed
+// with compression ("", "zlib", or "gzip").
+func encodeGIDs(t *testing.T, gids []int, compression string) string {
+	t.Helper()
+	var raw bytes.Buffer
+	for _, g := range gids {
+		binary.Write(&raw, binary.LittleEndian, uint32(g))
+	}
+	var out bytes.Buffer
+	var w io.WriteCloser
+	switch compression {
+	case "zlib":
+		w = zlib.NewWriter(&out)
+	case "gzip":
+		w = gzip.NewWriter(&out)
+	default:
+		out = raw
+	}
+	if w != nil {
+		w.Write(raw.Bytes())
+		w.Close()
+	}
+	return base64.StdEncoding.EncodeToString(out.Bytes())
+}
+
+func TestLayerDataEncodings(t *testing.T) {
+	gids := []int{0, 1, 2, 3 | flipHorizontal, 4 | flipVertical | flipDiagonal, 0}
+	tests := []struct {
+		name     string
+		encoding string
+		compress string
+	}{
+		{"plain array", "", ""},
+		{"csv", "csv", ""},
+		{"base64", "base64", ""},
+		{"base64 zlib", "base64", "zlib"},
+		{"base64 gzip", "base64", "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := json.Marshal(gids)
+			if tt.encoding == "base64" {
+				data, _ = json.Marshal(encodeGIDs(t, gids, tt.compress))
+			}
+			src := fmt.Sprintf(`{"name": "Collision", "type": "tilelayer", "width": 3, "height": 2,
+			 "encoding": %q, "compression": %q, "data": %s}`, tt.encoding, tt.compress, data)
+			var l Layer
+			if err := json.Unmarshal([]byte(src), &l); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(l.Data, gids) {
+				t.Errorf("data = %v, want %v", l.Data, gids)
+			}
+		})
+	}
+}
+
+func TestLayerDataErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"unknown encoding", `{"encoding": "xml", "data": "x"}`},
+		{"unknown compression", `{"encoding": "base64", "compression": "zstd", "data": "AQAAAA=="}`},
+		{"bad base64", `{"encoding": "base64", "data": "!!!"}`},
+		{"not zlib", `{"encoding": "base64", "compression": "zlib", "data": "AQAAAA=="}`},
+		{"partial tile", `{"encoding": "base64", "data": "AQAA"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l Layer
+			if err := json.Unmarshal([]byte(tt.src), &l); err == nil {
+				t.Errorf("decoded %s as %v, want an error", tt.src, l.Data)
+			}
+		})
+	}
+}
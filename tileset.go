@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"image"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// imageFS holds the tileset images maps can reference. Tilesets are looked
+// up by file name only, since Tiled writes paths relative to wherever the
+// map was saved.
+//
+//go:embed assets/*.png
+var imageFS embed.FS
+
+// tilesetImage is a tileset's image ready to draw from.
+type tilesetImage struct {
+	firstGID int
+	image    *ebiten.Image
+	columns  int
+}
+
+// imageName returns the file name of ts's image. An external tileset whose
+// .tsx couldn't be read is assumed to share the .tsx's base name.
+func (ts *Tileset) imageName() string {
+	if ts.Image != "" {
+		return path.Base(ts.Image)
+	}
+	if ts.ext != nil && ts.ext.image != "" {
+		return path.Base(ts.ext.image)
+	}
+	base := path.Base(ts.Source)
+	return strings.TrimSuffix(base, path.Ext(base)) + ".png"
+}
+
+// loadTilesetImages resolves the image for every tileset in the current map.
+// Images are decoded once and kept in g.sheets; a tileset whose image can't
+// be found falls back to the default tilesheet.
+func (g *Game) loadTilesetImages() {
+	if g.sheets == nil {
+		g.sheets = map[string]*ebiten.Image{}
+	}
+	g.tilesets = g.tilesets[:0]
+	for i := range g.tilemap.Tilesets {
+		ts := &g.tilemap.Tilesets[i]
+		name := ts.imageName()
+		img, ok := g.sheets[name]
+		if !ok {
+			img = g.tiles
+			if data, err := g.readAsset(imageFS, name); err != nil {
+				log.Printf("tileset %q: %v; using the default tilesheet", name, err)
+			} else if decoded, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+				log.Printf("tileset %q: %v; using the default tilesheet", name, err)
+			} else {
+				img = ebiten.NewImageFromImage(decoded)
+			}
+			g.sheets[name] = img
+		}
+		columns := ts.Columns
+		if columns <= 0 && ts.ext != nil {
+			columns = ts.ext.columns
+		}
+		if columns <= 0 {
+			columns = img.Bounds().Dx() / g.cfg.TileSize
+		}
+		g.tilesets = append(g.tilesets, tilesetImage{firstGID: ts.FirstGID, image: img, columns: columns})
+	}
+}
+
+// resolveTile returns the image and source rectangle to draw for a GID,
+// ignoring any flip flags. It returns a nil image for empty tiles or GIDs no
+// tileset covers.
+func (g *Game) resolveTile(gid int) (*ebiten.Image, image.Rectangle) {
+	gid = tileGID(gid)
+	if gid == 0 {
+		return nil, image.Rectangle{}
+	}
+	var found *tilesetImage
+	for i := range g.tilesets {
+		ts := &g.tilesets[i]
+		if ts.firstGID <= gid && (found == nil || ts.firstGID > found.firstGID) {
+			found = ts
+		}
+	}
+	if found == nil || found.columns <= 0 {
+		return nil, image.Rectangle{}
+	}
+	local := gid - found.firstGID
+	ts := g.cfg.TileSize
+	sx := (local % found.columns) * ts
+	sy := (local / found.columns) * ts
+	return found.image, image.Rect(sx, sy, sx+ts, sy+ts)
+}
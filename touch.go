@@ -0,0 +1,98 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// touchButtonSize is the side of each on-screen button, in internal pixels.
+const touchButtonSize = 20
+
+// touchControl is a control an on-screen button drives.
+type touchControl int
+
+const (
+	touchLeft touchControl = iota
+	touchRight
+	touchUp
+	touchDown
+	touchJump
+	touchControlCount
+)
+
+// touchButtons lays out a D-pad in the bottom-left corner and a jump button
+// in the bottom-right.
+var touchButtons = []struct {
+	control touchControl
+	label   string
+	anchor  HUDAnchor
+}{
+	{touchLeft, "<", HUDAnchor{Anchor: BottomLeft, OffsetX: 2, OffsetY: 22}},
+	{touchRight, ">", HUDAnchor{Anchor: BottomLeft, OffsetX: 42, OffsetY: 22}},
+	{touchUp, "^", HUDAnchor{Anchor: BottomLeft, OffsetX: 22, OffsetY: 42}},
+	{touchDown, "v", HUDAnchor{Anchor: BottomLeft, OffsetX: 22, OffsetY: 2}},
+	{touchJump, "A", HUDAnchor{Anchor: BottomRight, OffsetX: 6, OffsetY: 12}},
+}
+
+var (
+	touchButtonColor     = color.RGBA{0xff, 0xff, 0xff, 0x30}
+	touchButtonHeldColor = color.RGBA{0xff, 0xff, 0xff, 0x70}
+)
+
+// touchButtonAt returns the top-left corner of an on-screen button on a
+// screenW x screenH screen.
+func touchButtonAt(a HUDAnchor, screenW, screenH int) (float64, float64) {
+	return a.Position(touchButtonSize, touchButtonSize, screenW, screenH)
+}
+
+// readTouch samples the on-screen buttons. Touch positions are already in
+// internal screen pixels, so hit-testing works at any window scale. Jump
+// counts as pressed or released on the tick a touch enters or leaves its
+// button.
+func (in *InputState) readTouch() FrameInput {
+	in.touchIDs = ebiten.AppendTouchIDs(in.touchIDs[:0])
+	if len(in.touchIDs) > 0 {
+		in.Touch = true
+	}
+	var held [touchControlCount]bool
+	for _, id := range in.touchIDs {
+		tx, ty := ebiten.TouchPosition(id)
+		for _, b := range touchButtons {
+			x, y := touchButtonAt(b.anchor, in.ScreenW, in.ScreenH)
+			if float64(tx) >= x && float64(tx) < x+touchButtonSize && float64(ty) >= y && float64(ty) < y+touchButtonSize {
+				held[b.control] = true
+			}
+		}
+	}
+	f := FrameInput{
+		Left:  held[touchLeft],
+		Right: held[touchRight],
+		Up:    held[touchUp],
+		Down:  held[touchDown],
+		Jump:  held[touchJump],
+	}
+	f.JumpPressed = f.Jump && !in.touchHeld[touchJump]
+	f.JumpReleased = !f.Jump && in.touchHeld[touchJump]
+	in.touchHeld = held
+	return f
+}
+
+// drawTouchControls draws the on-screen buttons, once a touch has been seen,
+// highlighting the ones being held.
+func drawTouchControls(screen *ebiten.Image, in *InputState) {
+	if !in.Touch {
+		return
+	}
+	for _, b := range touchButtons {
+		x, y := touchButtonAt(b.anchor, screen.Bounds().Dx(), screen.Bounds().Dy())
+		clr := touchButtonColor
+		if in.touchHeld[b.control] {
+			clr = touchButtonHeldColor
+		}
+		vector.DrawFilledRect(screen, float32(x), float32(y), touchButtonSize, touchButtonSize, clr, false)
+		ebitenutil.DebugPrintAt(screen, b.label, int(x)+(touchButtonSize-hudCharWidth)/2, int(y)+2)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const fadeTicks = 20 // length of each half of a fade transition
+
+// Transition fades the screen to black, runs onBlack once it's fully dark
+// (typically to swap levels or move the player), then fades back in.
+type Transition struct {
+	tween    *Tween
+	fadingIn bool
+	onBlack  func()
+}
+
+// newFadeTransition starts a fade-out that calls onBlack at its midpoint.
+func newFadeTransition(onBlack func()) *Transition {
+	return &Transition{tween: NewTween(0, 1, fadeTicks, easeInOutQuad), onBlack: onBlack}
+}
+
+// Update advances the fade and reports whether it has finished.
+func (t *Transition) Update() bool {
+	t.tween.Update()
+	if !t.tween.Done() {
+		return false
+	}
+	if t.fadingIn {
+		return true
+	}
+	if t.onBlack != nil {
+		t.onBlack()
+	}
+	t.fadingIn = true
+	t.tween = NewTween(1, 0, fadeTicks, easeInOutQuad)
+	return false
+}
+
+// Draw darkens the whole screen by the fade's current amount.
+func (t *Transition) Draw(screen *ebiten.Image) {
+	alpha := uint8(t.tween.Value() * 0xff)
+	b := screen.Bounds()
+	vector.DrawFilledRect(screen, 0, 0, float32(b.Dx()), float32(b.Dy()), color.RGBA{0, 0, 0, alpha}, false)
+}
@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestFadeTransition(t *testing.T) {
+	calls := 0
+	tr := newFadeTransition(func() { calls++ })
+	prev := tr.tween.Value()
+	for i := 1; i <= 2*fadeTicks; i++ {
+		done := tr.Update()
+		if done != (i == 2*fadeTicks) {
+			t.Fatalf("update %d: done = %v", i, done)
+		}
+		wantCalls := 0
+		if i >= fadeTicks {
+			wantCalls = 1
+		}
+		if calls != wantCalls {
+			t.Fatalf("update %d: onBlack called %d times, want %d", i, calls, wantCalls)
+		}
+
+		v := tr.tween.Value()
+		switch {
+		case i == fadeTicks && v != 1:
+			t.Errorf("update %d: darkness %v when onBlack ran, want 1", i, v)
+		case i < fadeTicks && v < prev, i > fadeTicks && v > prev:
+			t.Errorf("update %d: darkness went from %v to %v, the wrong way", i, prev, v)
+		}
+		prev = v
+	}
+	if prev != 0 {
+		t.Errorf("darkness %v at the end, want 0", prev)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const triggerMessageSeconds = 3 // how long a "show message" event stays up
+
+// Trigger is an invisible area that fires an event when the player walks
+// into it.
+type Trigger struct {
+	obj    Object
+	event  string
+	once   bool // fire only the first time, not on every entry
+	fired  bool
+	inside bool // a player was overlapping it last tick
+}
+
+// triggerEvents maps a trigger's "event" property to what it does. Each
+// handler reads whatever other properties it needs from the trigger.
+var triggerEvents = map[string]func(g *Game, t *Trigger){
+	// "show message" puts the "text" property on screen for a few seconds.
+	"show message": func(g *Game, t *Trigger) {
+		g.message = t.obj.Properties.String("text")
+		g.messageTimer = triggerMessageSeconds * ebiten.DefaultTPS
+	},
+	// "spawn enemy" drops an enemy at the top-left of the object named by
+	// "target", or of the trigger itself. It falls from there to the floor
+	// and patrols around where it was spawned.
+	"spawn enemy": func(g *Game, t *Trigger) {
+		at := t.obj
+		if target := t.obj.Properties.String("target"); target != "" {
+			if o, ok := g.tilemap.objectNamed(target); ok {
+				at = o
+			}
+		}
+		g.enemies = append(g.enemies, Enemy{
+			x: at.X, y: at.Y, vx: enemySpeed,
+			minX: at.X - enemyPatrolRange, maxX: at.X + enemyPatrolRange,
+		})
+	},
+	// "open door" unlocks the door named by "target", key or no key.
+	"open door": func(g *Game, t *Trigger) {
+		target := t.obj.Properties.String("target")
+		for i, d := range g.doors {
+			if d.obj.Name == target {
+				g.openDoor(i)
+			}
+		}
+	},
+}
+
+// loadTriggers creates a Trigger for every object of type "trigger". The
+// "event" property names what it does and "once" stops it firing again
+// after the first time.
+func loadTriggers(m *TiledMap) []Trigger {
+	var triggers []Trigger
+	for _, o := range m.objectsOfType("trigger") {
+		triggers = append(triggers, Trigger{
+			obj:   o,
+			event: o.Properties.String("event"),
+			once:  o.Properties.Bool("once"),
+		})
+	}
+	return triggers
+}
+
+// checkTriggers fires every trigger a player has just walked into. A
+// trigger fires once on entry, then not again until every player has left
+// it.
+func (g *Game) checkTriggers() {
+	if g.messageTimer > 0 {
+		g.messageTimer--
+	}
+	for i := range g.triggers {
+		t := &g.triggers[i]
+		o := t.obj
+		_, inside := g.playerTouching(o.X, o.Y, o.Width, o.Height)
+		entered := inside && !t.inside
+		t.inside = inside
+		if !entered || (t.once && t.fired) {
+			continue
+		}
+		t.fired = true
+		fire, ok := triggerEvents[t.event]
+		if !ok {
+			log.Printf("trigger %q: unknown event %q", o.Name, t.event)
+			continue
+		}
+		debugf("checkTriggers - %q fired %q", o.Name, t.event)
+		fire(g, t)
+	}
+}
+
+// drawMessage shows the text of the last "show message" event while it's
+// up.
+func (g *Game) drawMessage(screen *ebiten.Image) {
+	if g.messageTimer > 0 && g.message != "" {
+		drawHUDText(screen, g.message, HUDAnchor{Anchor: Center, OffsetY: -2 * hudLineHeight}, 0)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"embed"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+)
+
+// tsxFS holds the external tilesets the embedded levels reference. Tiled
+// writes a map's tileset source relative to the map, so an embedded level's
+// "../maps_tiled/x.tsx" is looked up as maps_tiled/x.tsx here.
+//
+//go:embed maps_tiled/*.tsx
+var tsxFS embed.FS
+
+// externalTileset is what a .tsx file says about its tiles. It's kept apart
+// from the Tileset fields so SaveMap still writes just the source reference.
+type externalTileset struct {
+	name    string
+	image   string
+	columns int
+	tiles   []TileDef
+}
+
+// tsxData mirrors the parts of Tiled's XML tileset format the game uses.
+type tsxData struct {
+	Name    string `xml:"name,attr"`
+	Columns int    `xml:"columns,attr"`
+	Image   struct {
+		Source string `xml:"source,attr"`
+	} `xml:"image"`
+	Tiles []struct {
+		ID         int `xml:"id,attr"`
+		Properties []struct {
+			Name  string `xml:"name,attr"`
+			Type  string `xml:"type,attr"`
+			Value string `xml:"value,attr"`
+		} `xml:"properties>property"`
+		Frames []struct {
+			TileID   int `xml:"tileid,attr"`
+			Duration int `xml:"duration,attr"`
+		} `xml:"animation>frame"`
+	} `xml:"tile"`
+}
+
+// parseTSX decodes a .tsx tileset. Property values are converted to the
+// types the JSON format would have given them, so Properties reads both the
+// same way.
+func parseTSX(data []byte) (*externalTileset, error) {
+	var d tsxData
+	if err := xml.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+	ext := &externalTileset{name: d.Name, image: d.Image.Source, columns: d.Columns}
+	for _, t := range d.Tiles {
+		td := TileDef{ID: t.ID}
+		for _, f := range t.Frames {
+			td.Animation = append(td.Animation, AnimationFrame{TileID: f.TileID, Duration: f.Duration})
+		}
+		for _, p := range t.Properties {
+			var v any = p.Value
+			switch p.Type {
+			case "bool":
+				v = p.Value == "true"
+			case "int", "float":
+				f, err := strconv.ParseFloat(p.Value, 64)
+				if err != nil {
+					return nil, fmt.Errorf("tile %d property %q: %w", t.ID, p.Name, err)
+				}
+				v = f
+			}
+			td.Properties = append(td.Properties, Property{Name: p.Name, Type: p.Type, Value: v})
+		}
+		ext.tiles = append(ext.tiles, td)
+	}
+	return ext, nil
+}
+
+// tileDefs returns the per-tile data of ts, wherever it's defined: in the
+// map for embedded tilesets, in the .tsx for external ones.
+func (ts *Tileset) tileDefs() []TileDef {
+	if ts.ext != nil {
+		return ts.ext.tiles
+	}
+	return ts.Tiles
+}
+
+// readTSX returns the named .tsx, given relative to the level maps, from
+// g.assetDir if it's there, otherwise from tsxFS.
+func (g *Game) readTSX(source string) ([]byte, error) {
+	if g.assetDir != "" {
+		data, err := os.ReadFile(filepath.Join(g.assetDir, filepath.FromSlash(source)))
+		if !errors.Is(err, fs.ErrNotExist) {
+			return data, err
+		}
+	}
+	return tsxFS.ReadFile(path.Join("assets", source))
+}
+
+// loadExternalTilesets parses the .tsx of every external tileset in the
+// current map. One that can't be read is logged and left with no tile data.
+func (g *Game) loadExternalTilesets() {
+	for i := range g.tilemap.Tilesets {
+		ts := &g.tilemap.Tilesets[i]
+		if ts.Source == "" || ts.ext != nil {
+			continue
+		}
+		data, err := g.readTSX(ts.Source)
+		if err == nil {
+			ts.ext, err = parseTSX(data)
+		}
+		if err != nil {
+			log.Printf("tileset %q: %v", ts.Source, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Live physics tuning steps and limits. JumpSpeed is negative, so a
+// stronger jump is a smaller number.
+const (
+	tuneGravityStep = 0.05
+	tuneGravityMin  = 0.05
+	tuneGravityMax  = 1.0
+	tuneJumpStep    = 0.25
+	tuneJumpMin     = -10.0
+	tuneJumpMax     = -1.0
+)
+
+// tunePhysics lets gravity and jump strength be adjusted while playing, for
+// feel-testing values: 1 and 2 lower and raise gravity, 3 and 4 weaken and
+// strengthen the jump. The change applies to every player and the new
+// values are logged so good ones can be copied into DefaultConfig.
+func (g *Game) tunePhysics() {
+	physics := g.players[0].physics
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyDigit1):
+		physics.Gravity = max(physics.Gravity-tuneGravityStep, tuneGravityMin)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDigit2):
+		physics.Gravity = min(physics.Gravity+tuneGravityStep, tuneGravityMax)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDigit3):
+		physics.JumpSpeed = min(physics.JumpSpeed+tuneJumpStep, tuneJumpMax)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDigit4):
+		physics.JumpSpeed = max(physics.JumpSpeed-tuneJumpStep, tuneJumpMin)
+	default:
+		return
+	}
+	for i := range g.players {
+		g.players[i].physics = physics
+	}
+	log.Printf("physics: gravity %.2f, jump speed %.2f", physics.Gravity, physics.JumpSpeed)
+}
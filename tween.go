@@ -0,0 +1,94 @@
+package main
+
+import "math"
+
+// EaseFunc maps normalized time t in [0, 1] to an eased progress value.
+// Most easings return values in [0, 1]; overshooting ones like easeOutBack
+// briefly go past 1 before settling.
+type EaseFunc func(t float64) float64
+
+// easeLinear returns t unchanged.
+func easeLinear(t float64) float64 {
+	return t
+}
+
+// easeInQuad starts slow and accelerates.
+func easeInQuad(t float64) float64 {
+	return t * t
+}
+
+// easeOutQuad starts fast and decelerates.
+func easeOutQuad(t float64) float64 {
+	return 1 - (1-t)*(1-t)
+}
+
+// easeInOutQuad accelerates through the first half and decelerates through the second.
+func easeInOutQuad(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return 1 - math.Pow(-2*t+2, 2)/2
+}
+
+// easeOutCubic is a stronger version of easeOutQuad.
+func easeOutCubic(t float64) float64 {
+	return 1 - math.Pow(1-t, 3)
+}
+
+// easeOutBack overshoots the target slightly and then settles back onto it.
+func easeOutBack(t float64) float64 {
+	const c1 = 1.70158
+	const c3 = c1 + 1
+	return 1 + c3*math.Pow(t-1, 3) + c1*math.Pow(t-1, 2)
+}
+
+// Tween interpolates between two values over a fixed number of ticks.
+type Tween struct {
+	from, to float64
+	duration int // ticks
+	elapsed  int
+	ease     EaseFunc
+}
+
+// NewTween creates a tween from -> to lasting duration ticks. A nil ease
+// defaults to easeLinear.
+func NewTween(from, to float64, duration int, ease EaseFunc) *Tween {
+	if ease == nil {
+		ease = easeLinear
+	}
+	return &Tween{from: from, to: to, duration: duration, ease: ease}
+}
+
+// Update advances the tween by one tick.
+func (t *Tween) Update() {
+	if t.elapsed < t.duration {
+		t.elapsed++
+	}
+}
+
+// Progress returns the normalized (un-eased) time in [0, 1].
+func (t *Tween) Progress() float64 {
+	if t.duration <= 0 {
+		return 1
+	}
+	return float64(t.elapsed) / float64(t.duration)
+}
+
+// Value returns the current eased value between from and to.
+func (t *Tween) Value() float64 {
+	if t.Done() {
+		// Return the exact end value so callers can compare against it.
+		return t.to
+	}
+	return t.from + (t.to-t.from)*t.ease(t.Progress())
+}
+
+// Done reports whether the tween has reached the end of its duration.
+func (t *Tween) Done() bool {
+	return t.elapsed >= t.duration
+}
+
+// Reset rewinds the tween to its start.
+func (t *Tween) Reset() {
+	t.elapsed = 0
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEasings(t *testing.T) {
+	tests := []struct {
+		name string
+		ease EaseFunc
+		mid  float64 // value at t = 0.5
+	}{
+		{"linear", easeLinear, 0.5},
+		{"in quad", easeInQuad, 0.25},
+		{"out quad", easeOutQuad, 0.75},
+		{"in-out quad", easeInOutQuad, 0.5},
+		{"out cubic", easeOutCubic, 0.875},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, p := range []struct{ t, want float64 }{{0, 0}, {0.5, tt.mid}, {1, 1}} {
+				if got := tt.ease(p.t); math.Abs(got-p.want) > 1e-9 {
+					t.Errorf("ease(%v) = %v, want %v", p.t, got, p.want)
+				}
+			}
+			prev := tt.ease(0)
+			for i := 1; i <= 100; i++ {
+				got := tt.ease(float64(i) / 100)
+				if got < prev {
+					t.Fatalf("ease(%v) = %v, less than ease at the step before (%v)", float64(i)/100, got, prev)
+				}
+				prev = got
+			}
+		})
+	}
+}
+
+func TestEaseOutBackOvershoots(t *testing.T) {
+	if got := easeOutBack(0); math.Abs(got) > 1e-9 {
+		t.Errorf("easeOutBack(0) = %v, want 0", got)
+	}
+	if got := easeOutBack(1); math.Abs(got-1) > 1e-9 {
+		t.Errorf("easeOutBack(1) = %v, want 1", got)
+	}
+	peak := 0.0
+	for i := range 101 {
+		peak = max(peak, easeOutBack(float64(i)/100))
+	}
+	if peak <= 1 {
+		t.Errorf("easeOutBack peaks at %v, want it to overshoot 1", peak)
+	}
+}
+
+func TestTween(t *testing.T) {
+	tw := NewTween(10, 20, 4, nil)
+	want := []float64{12.5, 15, 17.5, 20}
+	if tw.Value() != 10 || tw.Done() {
+		t.Fatalf("new tween: value %v, done %v; want 10, not done", tw.Value(), tw.Done())
+	}
+	for i, w := range want {
+		tw.Update()
+		if got := tw.Value(); got != w {
+			t.Errorf("after %d ticks value = %v, want %v", i+1, got, w)
+		}
+		if done := tw.Done(); done != (i == len(want)-1) {
+			t.Errorf("after %d ticks done = %v", i+1, done)
+		}
+	}
+	tw.Update()
+	if tw.Value() != 20 || tw.Progress() != 1 {
+		t.Errorf("past the end: value %v, progress %v; want 20, 1", tw.Value(), tw.Progress())
+	}
+	tw.Reset()
+	if tw.Value() != 10 || tw.Done() {
+		t.Errorf("after Reset: value %v, done %v; want 10, not done", tw.Value(), tw.Done())
+	}
+}
+
+func TestTweenZeroDuration(t *testing.T) {
+	tw := NewTween(0, 5, 0, easeOutBack)
+	if !tw.Done() || tw.Value() != 5 || tw.Progress() != 1 {
+		t.Errorf("zero-length tween: done %v, value %v, progress %v; want done at 5", tw.Done(), tw.Value(), tw.Progress())
+	}
+}
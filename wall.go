@@ -0,0 +1,28 @@
+package main
+
+import "math"
+
+// Wall slide and wall jump tunables, in pixels per reference tick.
+const (
+	wallSlideSpeed = 1.0 // fastest the player slides down a wall they're pushing into
+	wallJumpPush   = 1.5 // horizontal speed of a wall jump as a multiple of walking speed
+	wallJumpTicks  = 10  // ticks after a wall jump before the player can steer again
+)
+
+// wallContact returns -1 if a solid tile is directly left of the player, 1
+// if one is directly right, and 0 if neither. One-way platforms don't count
+// as walls.
+func (p *Player) wallContact(collision *Layer) int {
+	solid := func(px, py float64) bool {
+		tx, ty := int(math.Floor(px/float64(p.tileSize))), int(math.Floor(py/float64(p.tileSize)))
+		return collision.solidAt(tx, ty) && !collision.tiles.isOneWay(collision.tileAt(tx, ty))
+	}
+	top, bottom := p.y, p.y+p.height-1
+	switch {
+	case solid(p.x-1, top) || solid(p.x-1, bottom):
+		return -1
+	case solid(p.x+p.width, top) || solid(p.x+p.width, bottom):
+		return 1
+	}
+	return 0
+}
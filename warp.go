@@ -0,0 +1,115 @@
+package main
+
+import "log"
+
+// findEntry returns the "entry" object with the given name.
+func findEntry(m *TiledMap, name string) (Object, bool) {
+	for _, o := range m.objectsOfType("entry") {
+		if o.Name == name {
+			return o, true
+		}
+	}
+	return Object{}, false
+}
+
+// placeAt puts the player's feet on the bottom center of o. Point objects
+// have no size, so the player stands exactly on the point.
+func (p *Player) placeAt(o Object) {
+	p.x = o.X + o.Width/2 - p.width/2
+	p.y = o.Y + o.Height - p.height
+	p.vx, p.vy = 0, 0
+	p.onLadder = false
+	p.onRope = false
+	p.onGround = false
+}
+
+// touchingWarp returns the "warp" object any player overlaps, if any.
+func (g *Game) touchingWarp() (Object, bool) {
+	for _, o := range g.tilemap.objectsOfType("warp") {
+		if _, ok := g.playerTouching(o.X, o.Y, o.Width, o.Height); ok {
+			return o, true
+		}
+	}
+	return Object{}, false
+}
+
+// checkWarps starts a transition when the player walks into a warp. A warp's
+// "level" property names the destination level (empty means the current one)
+// and "entry" names the entry object to arrive at. Warps only fire again once
+// the player has stepped off every warp, so arriving on top of one doesn't
+// bounce them straight back.
+func (g *Game) checkWarps() {
+	w, ok := g.touchingWarp()
+	if !ok {
+		g.warpArmed = true
+		return
+	}
+	if !g.warpArmed {
+		return
+	}
+	g.warpArmed = false
+	level := w.Properties.String("level")
+	entry := w.Properties.String("entry")
+	g.transition = newFadeTransition(func() {
+		g.warp(level, entry)
+	})
+}
+
+// warp moves the player to the named entry, loading level first if it
+// differs from the current one. Arriving in another level makes the entry
+// the respawn point; without the entry the players start at that level's
+// PlayerStart instead. Health and active effects carry over.
+func (g *Game) warp(level, entry string) {
+	changed := level != "" && level != g.level
+	if changed {
+		if err := g.loadLevel(level); err != nil {
+			log.Printf("warp: %v", err)
+			return
+		}
+	}
+	o, ok := findEntry(&g.tilemap, entry)
+	switch {
+	case ok:
+		for i := range g.players {
+			g.players[i].placeAt(o)
+		}
+		if changed {
+			g.respawnX, g.respawnY = g.players[0].x, g.players[0].y
+		}
+	case changed:
+		log.Printf("warp: level %q has no entry %q, starting at its PlayerStart", g.level, entry)
+		g.spawnPlayer()
+		return
+	default:
+		log.Printf("warp: level %q has no entry %q", g.level, entry)
+	}
+	mapW, mapH := g.mapSize()
+	g.camera.Snap(g.players, mapW, mapH)
+}
+
+// checkExits finishes the level when the player reaches an "exit" object.
+// The exit's "level" property names the level to go to next, falling back
+// to the map's "next" property; with neither, the exit does nothing.
+func (g *Game) checkExits() {
+	if g.transition != nil {
+		return
+	}
+	for _, o := range g.tilemap.objectsOfType("exit") {
+		if _, ok := g.playerTouching(o.X, o.Y, o.Width, o.Height); !ok {
+			continue
+		}
+		next := o.Properties.String("level")
+		if next == "" {
+			next = g.tilemap.StringProperty("next")
+		}
+		if next == "" {
+			return
+		}
+		g.transition = newFadeTransition(func() {
+			if err := g.startLevel(next); err != nil {
+				log.Printf("exit: %v", err)
+			}
+		})
+		return
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestWarpWithinLevel walks into a warp and checks the player arrives at its
+// entry once the screen has faded out, and that gameplay pauses meanwhile.
+func TestWarpWithinLevel(t *testing.T) {
+	g := testLevel()
+	objects := &g.tilemap.Layers[0].Objects
+	*objects = append(*objects,
+		Object{Type: "warp", X: 16, Y: 128, Width: 16, Height: 16,
+			Properties: Properties{{Name: "entry", Value: "B"}}},
+		Object{Name: "B", Type: "entry", X: 240, Y: 64, Width: 16, Height: 16},
+	)
+	g.warpArmed = true
+	g.Step(nil)
+	if g.transition == nil {
+		t.Fatal("touching the warp didn't start a transition")
+	}
+	p := &g.players[0]
+	x, y := p.x, p.y
+	for range fadeTicks - 1 {
+		g.Step(nil)
+	}
+	if p.x != x || p.y != y {
+		t.Fatalf("player moved to (%v, %v) while the screen faded out", p.x, p.y)
+	}
+	g.Step(nil)
+	if p.x != 240 || p.y != 64 {
+		t.Errorf("player at (%v, %v) once the screen was black, want the entry (240, 64)", p.x, p.y)
+	}
+	for range fadeTicks {
+		g.Step(nil)
+	}
+	if g.transition != nil {
+		t.Error("transition still running after fading back in")
+	}
+}
+
+// TestWarpToLevelMovesRespawn warps into another level and checks a player
+// who then dies respawns at the entry they arrived at, not at the old
+// level's start.
+func TestWarpToLevelMovesRespawn(t *testing.T) {
+	g := testLevel()
+	g.level = "test"
+	g.assetDir = t.TempDir()
+	other := TiledMap{Width: 30, Height: 10, Layers: []Layer{{
+		Name: "Objects",
+		Type: "objectgroup",
+		Objects: []Object{
+			{Name: "PlayerStart", X: 32, Y: 96, Width: 16, Height: 16},
+			{Name: "B", Type: "entry", X: 400, Y: 64, Width: 16, Height: 16},
+		},
+	}}}
+	writeLevel(t, g.assetDir, "other", other, time.Now())
+
+	g.warp("other", "B")
+	if g.level != "other" {
+		t.Fatalf("level %q after the warp, want %q", g.level, "other")
+	}
+	p := &g.players[0]
+	p.x, p.y = 200, 40
+	p.health = 0
+	g.respawn()
+	if p.x != 400 || p.y != 64 {
+		t.Errorf("respawned at (%v, %v) after warping, want the entry (400, 64)", p.x, p.y)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import "math"
+
+// Swimming tunables. Speeds are in pixels per reference tick.
+const (
+	waterGravityFactor = 0.3 // fraction of normal gravity while submerged
+	waterMaxFallSpeed  = 1.5 // sinking speed cap
+	waterSpeedFactor   = 0.6 // fraction of normal walking speed
+	swimStroke         = 0.4 // upward acceleration while holding Up
+	swimSpeed          = 1.5 // fastest the player can swim upward
+	waterJumpFactor    = 0.8 // fraction of a normal jump when leaving the water at the surface
+)
+
+// getWaterLayer returns the layer named "Water".
+func getWaterLayer(layers []Layer) *Layer {
+	return getLayerByName(layers, "Water")
+}
+
+// inWater reports whether the center of the player's box is in a water
+// tile.
+func (p *Player) inWater(water *Layer) bool {
+	if water == nil {
+		return false
+	}
+	tx := int(math.Floor((p.x + p.width/2) / float64(p.tileSize)))
+	ty := int(math.Floor((p.y + p.height/2) / float64(p.tileSize)))
+	return water.tileAt(tx, ty) != 0
+}
+
+// atWaterSurface reports whether the player's head is out of the water, so
+// a jump can carry them clear of it.
+func (p *Player) atWaterSurface(water *Layer) bool {
+	tx := int(math.Floor((p.x + p.width/2) / float64(p.tileSize)))
+	ty := int(math.Floor(p.y / float64(p.tileSize)))
+	return water.tileAt(tx, ty) == 0
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// maxZoom is the largest magnification + will zoom to.
+const maxZoom = 4
+
+// updateZoom handles + and - (on the main keys or the keypad), stepping the
+// zoom by whole numbers so pixels stay square. The camera is re-centered on
+// the player after each change.
+func (g *Game) updateZoom() {
+	zoom := max(g.camera.zoom, 1)
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEqual), inpututil.IsKeyJustPressed(ebiten.KeyKPAdd):
+		zoom = min(zoom+1, maxZoom)
+	case inpututil.IsKeyJustPressed(ebiten.KeyMinus), inpututil.IsKeyJustPressed(ebiten.KeyKPSubtract):
+		zoom = max(zoom-1, 1)
+	}
+	if zoom == max(g.camera.zoom, 1) {
+		return
+	}
+	g.camera.zoom = zoom
+	mapW, mapH := g.mapSize()
+	g.camera.Snap(g.players, mapW, mapH)
+}
+
+// zoomTarget returns the image to draw the world into: the screen itself at
+// 1x, otherwise an offscreen image the size of the camera's view that Draw
+// scales up with nearest-neighbour filtering.
+func (g *Game) zoomTarget(screen *ebiten.Image) *ebiten.Image {
+	if g.camera.zoom <= 1 {
+		return screen
+	}
+	w := (g.cfg.ScreenWidth + g.camera.zoom - 1) / g.camera.zoom
+	h := (g.cfg.ScreenHeight + g.camera.zoom - 1) / g.camera.zoom
+	if g.zoomed != nil {
+		if b := g.zoomed.Bounds(); b.Dx() == w && b.Dy() == h {
+			return g.zoomed
+		}
+		g.zoomed.Deallocate()
+	}
+	g.zoomed = ebiten.NewImage(w, h)
+	return g.zoomed
+}